@@ -0,0 +1,105 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Defaults of AnnotateConfig.
+const (
+	DefaultAnnotateComment     = "Released in {tag}"
+	DefaultAnnotateConcurrency = 4
+)
+
+// AnnotateConfig configures the annotation of the pull requests included in the published release.
+type AnnotateConfig struct {
+	// Enabled annotates the pull requests after publish. Same as --annotate option.
+	Enabled bool `json:"enabled"`
+	// Comment is posted on the pull requests. {tag} and {url} are replaced. Empty means DefaultAnnotateComment.
+	Comment string `json:"comment"`
+	// Label is added to the pull requests(e.g. released). Empty means no label.
+	Label string `json:"label"`
+	// CloseIssues closes the issues referenced with the closing keywords(e.g. Fixes #123) in the commits.
+	CloseIssues bool `json:"close_issues"`
+	// Concurrency is the number of requests running at the same time. Zero means DefaultAnnotateConcurrency.
+	Concurrency int `json:"concurrency"`
+}
+
+// closingIssueRe matches GitHub's closing keywords(https://docs.github.com/en/issues/tracking-your-work-with-issues/linking-a-pull-request-to-an-issue).
+var closingIssueRe = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#(\d+)\b`)
+
+// ClosingIssueNumbers returns the unique issue numbers referenced with the closing keywords in the commits in ascending order.
+func ClosingIssueNumbers(commits []Commit) []int {
+	seen := map[int]bool{}
+	for _, c := range commits {
+		for _, m := range closingIssueRe.FindAllStringSubmatch(c.Title+"\n"+c.Body, -1) {
+			n, err := strconv.Atoi(m[1])
+			if err == nil && n != c.PR {
+				seen[n] = true
+			}
+		}
+	}
+
+	numbers := []int{}
+	for n := range seen {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	return numbers
+}
+
+// AnnotatePullRequest posts the comment on the pull request and adds the label.
+// It returns true without changing anything when the pull request already has the comment.
+func AnnotatePullRequest(gdp Gdp, number int, comment string, label string) (bool, error) {
+	comments, err := gdp.GetComments(number)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range comments {
+		if strings.TrimSpace(c) == strings.TrimSpace(comment) {
+			return true, nil
+		}
+	}
+
+	if err := gdp.AddComment(number, comment); err != nil {
+		return false, err
+	}
+	if label != "" {
+		if err := gdp.AddLabels(number, []string{label}); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
+
+// runConcurrently calls fn for 0 to n-1 with at most concurrency goroutines, and returns the errors in order of the calls.
+func runConcurrently(n int, concurrency int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	jobs := make(chan int)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
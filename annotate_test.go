@@ -0,0 +1,118 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClosingIssueNumbers(t *testing.T) {
+	commits := []Commit{
+		{PR: 12, Title: "fix bug", Body: "Fixes #5\nrelated to #6"},
+		{PR: 13, Title: "add feature(closes #7)", Body: "Resolves: #5"},
+		{PR: 14, Title: "revert", Body: "fixed #14"},
+	}
+
+	expected := []int{5, 7}
+	if numbers := ClosingIssueNumbers(commits); !reflect.DeepEqual(numbers, expected) {
+		t.Errorf("Output=%v, Expected=%v", numbers, expected)
+	}
+}
+
+func TestRunConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	errs := runConcurrently(10, 3, func(i int) error {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if i == 4 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+
+	if peak > 3 {
+		t.Errorf("Concurrency=%d, Expected<=%d", peak, 3)
+	}
+	for i, err := range errs {
+		if (err != nil) != (i == 4) {
+			t.Errorf("Error[%d]=%v", i, err)
+		}
+	}
+}
+
+type FakeGdpAnnotate struct {
+	Gdp
+	mu       sync.Mutex
+	comments map[int][]string
+	labels   map[int][]string
+	closed   []int
+	failed   int
+}
+
+func (f *FakeGdpAnnotate) GetComments(number int) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.comments[number], nil
+}
+
+func (f *FakeGdpAnnotate) AddComment(number int, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if number == f.failed {
+		return errors.New("403 Forbidden")
+	}
+	f.comments[number] = append(f.comments[number], body)
+	return nil
+}
+
+func (f *FakeGdpAnnotate) AddLabels(number int, labels []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.labels[number] = append(f.labels[number], labels...)
+	return nil
+}
+
+func (f *FakeGdpAnnotate) CloseIssue(number int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = append(f.closed, number)
+	return nil
+}
+
+func newFakeGdpAnnotate() *FakeGdpAnnotate {
+	return &FakeGdpAnnotate{comments: map[int][]string{}, labels: map[int][]string{}}
+}
+
+func TestAnnotatePullRequest(t *testing.T) {
+	fake := newFakeGdpAnnotate()
+	fake.comments[10] = []string{"LGTM", "Released in v1.2.4\n"}
+
+	skipped, err := AnnotatePullRequest(fake, 10, "Released in v1.2.4", "released")
+	if err != nil || !skipped {
+		t.Errorf("Skipped=%v, Error=%v, Expected=%v", skipped, err, true)
+	}
+	skipped, err = AnnotatePullRequest(fake, 12, "Released in v1.2.4", "released")
+	if err != nil || skipped {
+		t.Errorf("Skipped=%v, Error=%v, Expected=%v", skipped, err, false)
+	}
+
+	if !reflect.DeepEqual(fake.comments[12], []string{"Released in v1.2.4"}) || !reflect.DeepEqual(fake.labels[12], []string{"released"}) {
+		t.Errorf("Comments=%q, Labels=%q", fake.comments[12], fake.labels[12])
+	}
+	if len(fake.labels[10]) > 0 {
+		t.Errorf("Labels=%q, Expected no label to the annotated pull request", fake.labels[10])
+	}
+}
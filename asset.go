@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ChecksumsFile is the file name of SHA-256 checksums of release assets.
+const ChecksumsFile = "checksums.txt"
+
+// stringsFlag is the flag which can be specified multiple times.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// ExpandAssets expands glob patterns of release assets into file paths.
+// The assets are uploaded by their base names, so the files having the same base name(including checksums.txt) are rejected.
+func ExpandAssets(patterns []string) ([]string, error) {
+	seen := map[string]bool{}
+	names := map[string]string{ChecksumsFile: "the generated checksums"}
+	files := []string{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("asset %q is not found", pattern)
+		}
+
+		for _, m := range matches {
+			if info, err := os.Stat(m); err != nil || info.IsDir() || seen[m] {
+				continue
+			}
+			seen[m] = true
+			name := filepath.Base(m)
+			if other, ok := names[name]; ok {
+				return nil, fmt.Errorf("asset %s has the same name as %s", m, other)
+			}
+			names[name] = m
+			files = append(files, m)
+		}
+	}
+
+	return files, nil
+}
+
+// WriteChecksums writes SHA-256 checksums of the files to checksums.txt in the directory.
+func WriteChecksums(files []string, dir string) (string, error) {
+	var b strings.Builder
+	for _, file := range files {
+		sum, err := sha256File(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s  %s\n", sum, filepath.Base(file))
+	}
+
+	path := filepath.Join(dir, ChecksumsFile)
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Retry's settings of uploading release assets.
+var (
+	uploadAttempts = 3
+	retryInterval  = 2 * time.Second
+)
+
+// retry calls fn until it succeeds or attempts are exhausted. The interval gets longer on each failure.
+func retry(attempts int, interval time.Duration, fn func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i < attempts-1 {
+			time.Sleep(interval * time.Duration(i+1))
+		}
+	}
+
+	return fmt.Errorf("%w(%d attempts)", err, attempts)
+}
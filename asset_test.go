@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpandAssets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"gdp_linux.tar.gz": "linux", "gdp_darwin.tar.gz": "darwin", "gdp.zip": "zip"})
+
+	files, err := ExpandAssets([]string{filepath.Join(dir, "*.tar.gz"), filepath.Join(dir, "gdp_*")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{filepath.Join(dir, "gdp_darwin.tar.gz"), filepath.Join(dir, "gdp_linux.tar.gz")}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Output=%v, Expected=%v", files, expected)
+	}
+}
+
+func TestExpandAssets_NotFound(t *testing.T) {
+	_, err := ExpandAssets([]string{filepath.Join(t.TempDir(), "*.tar.gz")})
+
+	expected := "is not found"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
+
+func TestExpandAssets_SameName(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"linux", "darwin"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFiles(t, filepath.Join(dir, sub), map[string]string{"app": sub})
+	}
+	writeFiles(t, dir, map[string]string{ChecksumsFile: "checksums"})
+
+	type pattern struct {
+		exp     string
+		pattern string
+	}
+	patterns := []pattern{
+		{"asset " + filepath.Join(dir, "linux", "app") + " has the same name as " + filepath.Join(dir, "darwin", "app"), filepath.Join(dir, "*", "app")},
+		{"asset " + filepath.Join(dir, ChecksumsFile) + " has the same name as the generated checksums", filepath.Join(dir, "*.txt")},
+	}
+
+	for _, p := range patterns {
+		_, err := ExpandAssets([]string{p.pattern})
+		if err == nil || err.Error() != p.exp {
+			t.Errorf("Output=%v, Expected=%q", err, p.exp)
+		}
+	}
+}
+
+func TestWriteChecksums(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.txt": "hello\n", "b.txt": ""})
+
+	path, err := WriteChecksums([]string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+
+	expected := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  a.txt\n"
+	expected = expected + "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  b.txt\n"
+	if string(b) != expected {
+		t.Errorf("Output=%q, Expected=%q", string(b), expected)
+	}
+	if filepath.Base(path) != ChecksumsFile {
+		t.Errorf("Output=%q, Expected=%q", filepath.Base(path), ChecksumsFile)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	count := 0
+	err := retry(3, 0, func() error {
+		count++
+		if count < 3 {
+			return errors.New("error occurred")
+		}
+		return nil
+	})
+	if err != nil || count != 3 {
+		t.Errorf("Error=%v, Count=%d, Expected=3", err, count)
+	}
+
+	count = 0
+	err = retry(2, 0, func() error {
+		count++
+		return errors.New("error occurred")
+	})
+
+	expected := "error occurred(2 attempts)"
+	if err == nil || err.Error() != expected || count != 2 {
+		t.Errorf("Output=%v, Count=%d, Expected=%q", err, count, expected)
+	}
+}
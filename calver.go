@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CalVerConfig configures the calendar versioning.
+type CalVerConfig struct {
+	// Layout is CalVer's format(e.g. YYYY.0M.0D or YY.0M.MICRO) or Go's time layout(e.g. 20060102-1504).
+	Layout string `json:"layout"`
+	// Timezone is the IANA timezone of the date(e.g. Asia/Tokyo). Empty means the local timezone.
+	Timezone string `json:"timezone"`
+}
+
+// CalVer is the calendar versioning scheme(https://calver.org/).
+type CalVer struct {
+	layout   string
+	parts    []calverPart
+	location *time.Location
+	// goLayout means the parts other than MICRO are Go's time layout.
+	goLayout bool
+}
+
+// calverPart is the token(e.g. YYYY) or the text between tokens.
+type calverPart struct {
+	token string
+	text  string
+}
+
+var calverTokenRe = regexp.MustCompile(`YYYY|MICRO|0Y|YY|0M|MM|0W|WW|0D|DD`)
+
+// calverTokenPatterns are the regular expressions matching the tokens.
+var calverTokenPatterns = map[string]string{
+	"YYYY":  `(\d{4})`,
+	"YY":    `(\d{1,3})`,
+	"0Y":    `(\d{2,3})`,
+	"MM":    `(\d{1,2})`,
+	"0M":    `(\d{2})`,
+	"WW":    `(\d{1,2})`,
+	"0W":    `(\d{2})`,
+	"DD":    `(\d{1,2})`,
+	"0D":    `(\d{2})`,
+	"MICRO": `(\d+)`,
+}
+
+// firstMicro is MICRO of the first tag of the date, same as the date format(e.g. 20180525.1).
+const firstMicro = 1
+
+// NewCalVer creates the calendar versioning scheme from the config.
+func NewCalVer(config CalVerConfig) (*CalVer, error) {
+	if config.Layout == "" {
+		return nil, errors.New("CalVer layout is empty")
+	}
+
+	location := time.Local
+	if config.Timezone != "" {
+		l, err := time.LoadLocation(config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", config.Timezone, err)
+		}
+		location = l
+	}
+
+	c := &CalVer{layout: config.Layout, location: location, goLayout: true}
+	rest := config.Layout
+	for {
+		loc := calverTokenRe.FindStringIndex(rest)
+		if loc == nil {
+			break
+		}
+		if loc[0] > 0 {
+			c.parts = append(c.parts, calverPart{text: rest[:loc[0]]})
+		}
+		token := rest[loc[0]:loc[1]]
+		c.parts = append(c.parts, calverPart{token: token})
+		c.goLayout = c.goLayout && token == "MICRO"
+		rest = rest[loc[1]:]
+	}
+	if rest != "" {
+		c.parts = append(c.parts, calverPart{text: rest})
+	}
+
+	// the layout must change with the date.
+	t := time.Date(2001, 2, 3, 4, 5, 6, 0, location)
+	if c.format(t, firstMicro) == c.format(t.AddDate(1, 1, 8).Add(time.Hour+time.Minute), firstMicro) {
+		return nil, fmt.Errorf("CalVer layout %q has no date", config.Layout)
+	}
+
+	return c, nil
+}
+
+// Name implements TagScheme.
+func (c *CalVer) Name() string {
+	return "calver"
+}
+
+// Parse implements TagScheme. The numbers are in order of the layout.
+func (c *CalVer) Parse(tag string) (TagVersion, error) {
+	re, _ := c.pattern()
+	m := re.FindStringSubmatch(tag)
+	if m == nil {
+		return TagVersion{}, fmt.Errorf("tag %q does not match the CalVer layout %q", tag, c.layout)
+	}
+
+	v := TagVersion{Tag: tag, Scheme: c.Name(), Prefix: m[1]}
+	for _, n := range m[2:] {
+		i, err := strconv.Atoi(n)
+		if err != nil {
+			return TagVersion{}, err
+		}
+		v.Numbers = append(v.Numbers, i)
+	}
+
+	return v, nil
+}
+
+// Next gets the next version of the tag at the current date. The tag's prefix(e.g. release_) is kept.
+// Empty tag means the first version.
+func (c *CalVer) Next(tag string) (string, error) {
+	t := now()
+	if tag == "" {
+		return c.format(t, firstMicro), nil
+	}
+
+	re, microGroup := c.pattern()
+	m := re.FindStringSubmatch(tag)
+	if m == nil {
+		return "", fmt.Errorf("latest tag %q does not match the CalVer layout %q", tag, c.layout)
+	}
+
+	prefix := m[1]
+	if microGroup < 0 {
+		next := prefix + c.format(t, 0)
+		if next == tag {
+			return "", fmt.Errorf("next tag of %q is the same at the current date. Add MICRO to the CalVer layout %q", tag, c.layout)
+		}
+		return next, nil
+	}
+
+	micro, err := strconv.Atoi(m[microGroup])
+	if err != nil {
+		return "", err
+	}
+	if prefix+c.format(t, micro) == tag {
+		return prefix + c.format(t, micro+1), nil
+	}
+
+	return prefix + c.format(t, firstMicro), nil
+}
+
+// Compare implements TagScheme.
+func (c *CalVer) Compare(a TagVersion, b TagVersion) int {
+	return compareNumbers(a.Numbers, b.Numbers)
+}
+
+// Validate implements TagScheme.
+func (c *CalVer) Validate(tag string) error {
+	_, err := c.Parse(tag)
+	return err
+}
+
+// format formats the time in the timezone according to the layout.
+func (c *CalVer) format(t time.Time, micro int) string {
+	t = t.In(c.location)
+	_, week := t.ISOWeek()
+
+	var b strings.Builder
+	for _, p := range c.parts {
+		switch p.token {
+		case "":
+			if c.goLayout {
+				b.WriteString(t.Format(p.text))
+			} else {
+				b.WriteString(p.text)
+			}
+		case "YYYY":
+			fmt.Fprintf(&b, "%d", t.Year())
+		case "YY":
+			fmt.Fprintf(&b, "%d", t.Year()-2000)
+		case "0Y":
+			fmt.Fprintf(&b, "%02d", t.Year()-2000)
+		case "MM":
+			fmt.Fprintf(&b, "%d", t.Month())
+		case "0M":
+			fmt.Fprintf(&b, "%02d", t.Month())
+		case "WW":
+			fmt.Fprintf(&b, "%d", week)
+		case "0W":
+			fmt.Fprintf(&b, "%02d", week)
+		case "DD":
+			fmt.Fprintf(&b, "%d", t.Day())
+		case "0D":
+			fmt.Fprintf(&b, "%02d", t.Day())
+		case "MICRO":
+			fmt.Fprintf(&b, "%d", micro)
+		}
+	}
+
+	return b.String()
+}
+
+var digitsRe = regexp.MustCompile(`\d+`)
+
+// pattern returns the regular expression matching the tag of the layout and the group of MICRO(-1 means no MICRO).
+// The first group is the prefix and the other groups are the numbers.
+func (c *CalVer) pattern() (*regexp.Regexp, int) {
+	var b strings.Builder
+	// the prefix does not end with a number not to take the number of the version.
+	b.WriteString(`^((?:.*\D)?)`)
+	groups, microGroup := 1, -1
+	for _, p := range c.parts {
+		switch {
+		case p.token != "":
+			groups++
+			if p.token == "MICRO" {
+				microGroup = groups
+			}
+			b.WriteString(calverTokenPatterns[p.token])
+		case c.goLayout:
+			// numbers of Go's time layout may not be zero padded(e.g. 1 for January).
+			text := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(p.text)
+			quoted := []string{}
+			for _, s := range digitsRe.Split(text, -1) {
+				quoted = append(quoted, regexp.QuoteMeta(s))
+			}
+			groups += len(quoted) - 1
+			b.WriteString(strings.Join(quoted, `(\d+)`))
+		default:
+			b.WriteString(regexp.QuoteMeta(p.text))
+		}
+	}
+	b.WriteString(`$`)
+
+	return regexp.MustCompile(b.String()), microGroup
+}
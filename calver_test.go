@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalVer_Next(t *testing.T) {
+	type pattern struct {
+		exp    string
+		layout string
+		tag    string
+	}
+	patterns := []pattern{
+		{"2020.04.01", "YYYY.0M.0D", "2020.03.31"},
+		{"2020.4.1", "YYYY.MM.DD", ""},
+		{"20.04.1", "YY.0M.MICRO", "20.03.5"},
+		{"20.04.6", "YY.0M.MICRO", "20.04.5"},
+		{"api/20.04.6", "YY.0M.MICRO", "api/20.04.5"},
+		{"2020.14.1", "YYYY.0W.MICRO", "2020.13.2"},
+		{"20200401-1730", "20060102-1504", "20200331-0900"},
+		{"release_20200401.3", "20060102.MICRO", "release_20200401.2"},
+		{"release_20200401.1", "20060102.MICRO", "release_20200331.2"},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 30, 00, 0, time.Local))
+
+	for _, p := range patterns {
+		calver, err := NewCalVer(CalVerConfig{Layout: p.layout})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		next, err := calver.Next(p.tag)
+		if err != nil {
+			t.Errorf("Error=%q, Layout=%q, Tag=%q", err.Error(), p.layout, p.tag)
+			continue
+		}
+		if next != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Layout=%q, Tag=%q", next, p.exp, p.layout, p.tag)
+		}
+	}
+}
+
+func TestCalVer_NextTimezone(t *testing.T) {
+	fakeNow(t, time.Date(2020, 4, 1, 17, 30, 00, 0, time.UTC))
+
+	calver, err := NewCalVer(CalVerConfig{Layout: "YYYY.0M.0D", Timezone: "Asia/Tokyo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, _ := calver.Next("2020.03.31")
+	expected := "2020.04.02"
+	if next != expected {
+		t.Errorf("Output=%q, Expected=%q", next, expected)
+	}
+}
+
+func TestCalVer_NextError(t *testing.T) {
+	type pattern struct {
+		exp    string
+		layout string
+		tag    string
+	}
+	patterns := []pattern{
+		{`latest tag "v1.2.3" does not match the CalVer layout "YYYY.0M.0D"`, "YYYY.0M.0D", "v1.2.3"},
+		{`next tag of "2020.04.01" is the same at the current date`, "YYYY.0M.0D", "2020.04.01"},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 30, 00, 0, time.Local))
+
+	for _, p := range patterns {
+		calver, err := NewCalVer(CalVerConfig{Layout: p.layout})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = calver.Next(p.tag)
+		if err == nil || !strings.Contains(err.Error(), p.exp) {
+			t.Errorf("Output=%v, Expected=%q", err, p.exp)
+		}
+	}
+}
+
+func TestNewCalVer_Invalid(t *testing.T) {
+	type pattern struct {
+		exp    string
+		config CalVerConfig
+	}
+	patterns := []pattern{
+		{"CalVer layout is empty", CalVerConfig{}},
+		{`CalVer layout "release-MICRO" has no date`, CalVerConfig{Layout: "release-MICRO"}},
+		{`invalid timezone "Mars/Olympus"`, CalVerConfig{Layout: "YYYY.0M.0D", Timezone: "Mars/Olympus"}},
+	}
+
+	for _, p := range patterns {
+		_, err := NewCalVer(p.config)
+		if err == nil || !strings.Contains(err.Error(), p.exp) {
+			t.Errorf("Output=%v, Expected=%q", err, p.exp)
+		}
+	}
+}
+
+func TestCalVer_Parse(t *testing.T) {
+	type pattern struct {
+		exp    []int
+		layout string
+		tag    string
+	}
+	patterns := []pattern{
+		{[]int{2020, 4, 1}, "YYYY.0M.0D", "2020.04.01"},
+		{[]int{20, 4, 12}, "YY.0M.MICRO", "api/20.04.12"},
+		{[]int{20200401, 1730}, "20060102-1504", "20200401-1730"},
+	}
+
+	for _, p := range patterns {
+		calver, err := NewCalVer(CalVerConfig{Layout: p.layout})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := calver.Parse(p.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(v.Numbers, p.exp) {
+			t.Errorf("Output=%v, Expected=%v, Layout=%q, Tag=%q", v.Numbers, p.exp, p.layout, p.tag)
+		}
+	}
+}
@@ -2,20 +2,24 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/mitchellh/colorstring"
 )
 
-// CLI has stdout/stderr's writer and Gdp's interface.
+// CLI has stdout/stderr's writer, Gdp's interface and the project config.
 type CLI struct {
 	outStream io.Writer
 	errStream io.Writer
 	gdp       Gdp
+	config    Config
 }
 
 // Exit code.
@@ -26,8 +30,15 @@ const (
 
 // Sub command name.
 const (
-	CommandDeploy  = "deploy"
-	CommandPublish = "publish"
+	CommandDeploy   = "deploy"
+	CommandPublish  = "publish"
+	CommandRelease  = "release"
+	CommandList     = "list"
+	CommandStatus   = "status"
+	CommandDiff     = "diff"
+	CommandPrepare  = "prepare"
+	CommandFinalize = "finalize"
+	CommandPromote  = "promote"
 )
 
 // Safety Hour.
@@ -36,12 +47,44 @@ const (
 	SafetyHourEnd   = 19
 )
 
-// Run invokes deploy and publish's process.
+// options are the flags of deploy, publish and release.
+type options struct {
+	dryRun         bool
+	force          bool
+	tag            string
+	strategy       CommitStrategy
+	trackers       []IssueTracker
+	exportIssues   string
+	publish        PublishOptions
+	finalize       bool
+	update         bool
+	assets         []string
+	rollback       bool
+	notifiers      []Notifier
+	limit          int
+	since          string
+	sinceDate      string
+	json           bool
+	group          bool
+	component      Component
+	scheme         TagScheme
+	allowDowngrade bool
+	versionFiles   []VersionFile
+	annotate       bool
+	milestone      bool
+	environment    string
+	from           Environment
+	to             Environment
+}
+
+// Run invokes deploy, publish and release's process.
 func (cli *CLI) Run(args []string) int {
 	var version bool
-	var dryRun bool
-	var force bool
-	var tag string
+	var opts options
+	var strategyName string
+	var componentName string
+	var fromName, toName string
+	var assets stringsFlag
 
 	flags := flag.NewFlagSet("gdp", flag.ContinueOnError)
 	flags.SetOutput(cli.errStream)
@@ -51,12 +94,33 @@ func (cli *CLI) Run(args []string) int {
 
 	flags.BoolVar(&version, "version", false, "")
 	flags.BoolVar(&version, "v", false, "")
-	flags.BoolVar(&dryRun, "dry-run", false, "")
-	flags.BoolVar(&dryRun, "d", false, "")
-	flags.BoolVar(&force, "force", false, "")
-	flags.BoolVar(&force, "f", false, "")
-	flags.StringVar(&tag, "tag", "", "")
-	flags.StringVar(&tag, "t", "", "")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "")
+	flags.BoolVar(&opts.dryRun, "d", false, "")
+	flags.BoolVar(&opts.force, "force", false, "")
+	flags.BoolVar(&opts.force, "f", false, "")
+	flags.StringVar(&opts.tag, "tag", "", "")
+	flags.StringVar(&opts.tag, "t", "", "")
+	flags.StringVar(&strategyName, "strategy", cli.config.Strategy, "")
+	flags.StringVar(&opts.exportIssues, "export-issues", cli.config.Issues.Export, "")
+	flags.BoolVar(&opts.publish.Draft, "draft", false, "")
+	flags.BoolVar(&opts.publish.Prerelease, "prerelease", false, "")
+	flags.StringVar(&opts.publish.Latest, "latest", "", "")
+	flags.BoolVar(&opts.finalize, "finalize", false, "")
+	flags.BoolVar(&opts.update, "update", false, "")
+	flags.Var(&assets, "asset", "")
+	flags.BoolVar(&opts.rollback, "rollback", cli.config.Rollback, "")
+	flags.IntVar(&opts.limit, "limit", 10, "")
+	flags.StringVar(&opts.since, "since", "", "")
+	flags.StringVar(&opts.sinceDate, "since-date", "", "")
+	flags.BoolVar(&opts.json, "json", false, "")
+	flags.BoolVar(&opts.group, "group", false, "")
+	flags.StringVar(&componentName, "component", "", "")
+	flags.BoolVar(&opts.allowDowngrade, "allow-downgrade", false, "")
+	flags.BoolVar(&opts.annotate, "annotate", cli.config.Annotate.Enabled, "")
+	flags.BoolVar(&opts.milestone, "milestone", cli.config.Milestone.Enabled, "")
+	flags.StringVar(&opts.environment, "environment", "", "")
+	flags.StringVar(&fromName, "from", "", "")
+	flags.StringVar(&toName, "to", "", "")
 
 	if len(args) < 2 {
 		printError(cli.errStream, "Too few argument.")
@@ -64,12 +128,23 @@ func (cli *CLI) Run(args []string) int {
 		return ExitError
 	}
 
+	subCommand := args[1]
 	parseIndex := 1
-	if args[1] == CommandDeploy || args[1] == CommandPublish {
+	if isSubCommand(subCommand) {
 		parseIndex++
 	}
-	if err := flags.Parse(args[parseIndex:]); err != nil {
-		return ExitError
+	// flags can be put after the positional arguments(e.g. gdp diff FROM TO --group).
+	positional := []string{}
+	rest := args[parseIndex:]
+	for {
+		if err := flags.Parse(rest); err != nil {
+			return ExitError
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		positional = append(positional, flags.Arg(0))
+		rest = flags.Args()[1:]
 	}
 
 	if version {
@@ -77,121 +152,1602 @@ func (cli *CLI) Run(args []string) int {
 		return ExitSuccess
 	}
 
-	parsedArgs := flags.Args()
-	len := len(parsedArgs)
-	if len > 1 {
+	var diffRefs []string
+	if subCommand == CommandDiff {
+		if len(positional) < 2 {
+			printError(cli.errStream, "Too few argument.")
+			printError(cli.errStream, Usage)
+			return ExitError
+		}
+		diffRefs, positional = positional[:2], positional[2:]
+	}
+	if len(positional) > 1 {
 		printError(cli.errStream, "Too many argument.")
 		printError(cli.errStream, Usage)
 		return ExitError
 	}
 
-	subCommand := args[1]
-	if subCommand != CommandDeploy && subCommand != CommandPublish {
+	if !isSubCommand(subCommand) {
 		printError(cli.errStream, "Invalid sub command.")
 		printError(cli.errStream, Usage)
 		return ExitError
 	}
 
-	if tag == "" {
-		latestTag := cli.gdp.GetLatestTag()
-		if subCommand == CommandDeploy {
-			next, err := GetNextVersion(latestTag)
+	strategy, err := ParseCommitStrategy(strategyName)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Invalid option: %s.", err.Error()))
+		return ExitError
+	}
+	opts.strategy = strategy
+
+	opts.component, err = NewComponent(componentName, cli.config.Components)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Invalid option: %s.", err.Error()))
+		return ExitError
+	}
+	opts.scheme, err = NewTagScheme(cli.config)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Invalid config: %s.", err.Error()))
+		return ExitError
+	}
+	if by := cli.config.LatestTag.By; by != "" && by != LatestTagByDescribe && by != LatestTagByVersion {
+		printError(cli.errStream, fmt.Sprintf("Invalid config: latest_tag.by must be %s or %s.", LatestTagByDescribe, LatestTagByVersion))
+		return ExitError
+	}
+	if opts.tag != "" && !opts.component.Owns(opts.tag) {
+		printError(cli.errStream, fmt.Sprintf("Invalid option: tag must have the component's prefix %s.", opts.component.Prefix()))
+		return ExitError
+	}
+
+	// pre-release is enabled automatically by the tag unless --prerelease is specified.
+	explicitPrerelease, explicitRollback, explicitAnnotate, explicitMilestone, explicitLimit := false, false, false, false, false
+	flags.Visit(func(f *flag.Flag) {
+		explicitPrerelease = explicitPrerelease || f.Name == "prerelease"
+		explicitRollback = explicitRollback || f.Name == "rollback"
+		explicitAnnotate = explicitAnnotate || f.Name == "annotate"
+		explicitMilestone = explicitMilestone || f.Name == "milestone"
+		explicitLimit = explicitLimit || f.Name == "limit"
+	})
+	readOnly := subCommand == CommandList || subCommand == CommandStatus || subCommand == CommandDiff
+	if readOnly && (opts.tag != "" || opts.dryRun || opts.force) {
+		printError(cli.errStream, "Invalid option: --tag, --dry-run and --force are not available for list, status and diff.")
+		return ExitError
+	}
+	if subCommand != CommandList && (explicitLimit || opts.json) {
+		printError(cli.errStream, "Invalid option: --limit and --json are available for list.")
+		return ExitError
+	}
+	if subCommand != CommandDiff && opts.group {
+		printError(cli.errStream, "Invalid option: --group is available for diff.")
+		return ExitError
+	}
+	// --since is the ref of the release note's range, while list filters the tags by the date of --since-date.
+	if (subCommand == CommandList || subCommand == CommandDiff) && opts.since != "" {
+		printError(cli.errStream, "Invalid option: --since is not available for list and diff. Run list with --since-date to show tags created since the date.")
+		return ExitError
+	}
+	if subCommand != CommandList && opts.sinceDate != "" {
+		printError(cli.errStream, "Invalid option: --since-date is available for list.")
+		return ExitError
+	}
+	if subCommand != CommandPublish && subCommand != CommandRelease && subCommand != CommandFinalize && (opts.publish != PublishOptions{} || len(assets) > 0) {
+		printError(cli.errStream, "Invalid option: --draft, --prerelease, --latest and --asset are available for publish, release and finalize.")
+		return ExitError
+	}
+	if subCommand != CommandPublish && (opts.finalize || opts.update) {
+		printError(cli.errStream, "Invalid option: --finalize and --update are available for publish.")
+		return ExitError
+	}
+	if latest := opts.publish.Latest; latest != "" && latest != "true" && latest != "false" && latest != "legacy" {
+		printError(cli.errStream, "Invalid option: --latest must be true, false or legacy.")
+		return ExitError
+	}
+
+	if subCommand != CommandRelease && subCommand != CommandFinalize && explicitRollback {
+		printError(cli.errStream, "Invalid option: --rollback is available for release and finalize.")
+		return ExitError
+	}
+	if subCommand != CommandPublish && subCommand != CommandRelease && subCommand != CommandFinalize && explicitAnnotate {
+		printError(cli.errStream, "Invalid option: --annotate is available for publish, release and finalize.")
+		return ExitError
+	}
+	if subCommand != CommandDeploy && opts.environment != "" {
+		printError(cli.errStream, "Invalid option: --environment is available for deploy.")
+		return ExitError
+	}
+	if subCommand != CommandPublish && subCommand != CommandRelease {
+		if explicitMilestone {
+			printError(cli.errStream, "Invalid option: --milestone is available for publish and release.")
+			return ExitError
+		}
+		// milestone.enabled of the project config is the default of publish and release only.
+		opts.milestone = false
+	}
+	if subCommand != CommandPromote && (fromName != "" || toName != "") {
+		printError(cli.errStream, "Invalid option: --from and --to are available for promote.")
+		return ExitError
+	}
+	if subCommand == CommandPromote && opts.component.Name != "" {
+		printError(cli.errStream, "Invalid option: --component is not available for promote.")
+		return ExitError
+	}
+
+	opts.assets = assets
+	if len(opts.assets) == 0 && (subCommand == CommandPublish || subCommand == CommandRelease || subCommand == CommandFinalize) {
+		opts.assets = cli.config.Assets
+	}
+
+	opts.trackers, err = NewIssueTrackers(cli.config.Issues.Patterns)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Invalid config: %s.", err.Error()))
+		return ExitError
+	}
+
+	if subCommand == CommandList {
+		return cli.list(opts)
+	}
+	if subCommand == CommandStatus {
+		return cli.status(opts)
+	}
+	if subCommand == CommandDiff {
+		return cli.diff(opts, diffRefs[0], diffRefs[1])
+	}
+
+	opts.notifiers, err = NewNotifiers(cli.config.Notifications, subCommand)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Invalid config: %s.", err.Error()))
+		return ExitError
+	}
+
+	if subCommand == CommandPromote {
+		if fromName == "" || toName == "" {
+			printError(cli.errStream, "Invalid option: promote requires --from and --to.")
+			return ExitError
+		}
+		if fromName == toName {
+			printError(cli.errStream, "Invalid option: --from and --to must be different environments.")
+			return ExitError
+		}
+		if opts.from, err = NewEnvironment(fromName, cli.config.Environments); err != nil {
+			printError(cli.errStream, fmt.Sprintf("Invalid option: %s.", err.Error()))
+			return ExitError
+		}
+		if opts.to, err = NewEnvironment(toName, cli.config.Environments); err != nil {
+			printError(cli.errStream, fmt.Sprintf("Invalid option: %s.", err.Error()))
+			return ExitError
+		}
+		return cli.promote(opts)
+	}
+
+	if subCommand != CommandPublish {
+		// the component's version files replace the repository's.
+		configs := cli.config.VersionFiles.Files
+		if opts.component.Name != "" {
+			configs = opts.component.VersionFiles
+		}
+		opts.versionFiles, err = NewVersionFiles(configs)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Invalid config: %s.", err.Error()))
+			return ExitError
+		}
+	}
+
+	if opts.tag == "" {
+		latestTag, err := cli.latestTag(opts)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting latest tag error: %s.", err.Error()))
+			return ExitError
+		}
+		if subCommand != CommandPublish {
+			next, err := opts.component.NextVersion(latestTag, opts.scheme)
 			if err != nil {
 				printError(cli.errStream, fmt.Sprintf("Getting release tag error: %s.", err.Error()))
 				return ExitError
 			}
 			latestTag = next
 		}
-		tag = latestTag
+		opts.tag = latestTag
 	}
+	if !explicitPrerelease {
+		opts.publish.Prerelease = IsPrerelease(opts.tag)
+	}
+
+	if subCommand == CommandRelease {
+		return cli.release(opts)
+	}
+	if subCommand == CommandPrepare {
+		return cli.prepare(opts)
+	}
+	if subCommand == CommandFinalize {
+		return cli.finalize(opts)
+	}
+
+	return cli.run(subCommand, opts)
+}
+
+// run invokes deploy or publish's process.
+func (cli *CLI) run(subCommand string, opts options) int {
+	tag := opts.tag
 
 	// validation
-	if !force && !validate(cli, subCommand, tag) {
+	if !opts.force && !validate(cli, subCommand, tag, opts) {
 		return ExitError
 	}
 
+	if opts.finalize {
+		if opts.dryRun {
+			printSuccess(cli.outStream, fmt.Sprintf("gdp %s --finalize done(dry-run mode).", subCommand))
+			return ExitSuccess
+		}
+		if err := cli.gdp.FinalizeRelease(tag, opts.publish.Latest); err != nil {
+			printError(cli.errStream, fmt.Sprintf("Finalize execution error: %s.", err.Error()))
+			return ExitError
+		}
+		printSuccess(cli.outStream, fmt.Sprintf("gdp %s --finalize done.", subCommand))
+		return ExitSuccess
+	}
+
 	toTag := "HEAD"
 	if subCommand == CommandPublish {
 		toTag = tag
 	}
 
 	// show release note
-	list, err := cli.gdp.GetMergeCommitList(toTag)
-	if err != nil {
-		printError(cli.errStream, fmt.Sprintf("Getting merge commit error: %s.", err.Error()))
+	fromTag := cli.fromTag(tag, toTag, opts)
+	note, commits, ok := cli.releaseNote(tag, fromTag, toTag, opts)
+	if !ok {
 		return ExitError
 	}
 
-	note := GetReleaseNote(tag, list)
-	fmt.Fprintln(cli.outStream, "The release note is as follows.")
-	fmt.Fprintln(cli.outStream, "====================================")
-	fmt.Fprintln(cli.outStream, note)
-	fmt.Fprintln(cli.outStream, "====================================")
+	var changes []FileChange
+	if subCommand == CommandDeploy {
+		changes, ok = cli.versionFileChanges(tag, opts)
+		if !ok {
+			return ExitError
+		}
+	}
 
-	if dryRun {
+	var assetFiles []string
+	if subCommand == CommandPublish {
+		files, cleanup, ok := cli.prepareAssets(opts.assets)
+		if !ok {
+			return ExitError
+		}
+		defer cleanup()
+		assetFiles = files
+	}
+
+	var existing *Release
+	if subCommand == CommandPublish {
+		var err error
+		existing, err = cli.gdp.GetRelease(tag)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting release error: %s.", err.Error()))
+			return ExitError
+		}
+	}
+	if existing != nil {
+		stored, regenerated := normalizeNote(existing.Message()), normalizeNote(note)
+		if stored == regenerated {
+			printSuccess(cli.outStream, fmt.Sprintf("The release of %s is already up to date.", tag))
+			return cli.uploadAssets(tag, assetFiles, opts)
+		}
+
+		fmt.Fprintln(cli.outStream, "The release already exists. The difference from the release note is as follows.")
+		fmt.Fprintln(cli.outStream, "====================================")
+		fmt.Fprintln(cli.outStream, DiffLines(stored, regenerated))
+		fmt.Fprintln(cli.outStream, "====================================")
+
+		if !opts.update {
+			printSuccess(cli.outStream, "Skipped publishing. Run with --update to update the release.")
+			return cli.uploadAssets(tag, assetFiles, opts)
+		}
+	}
+
+	if opts.dryRun {
 		printSuccess(cli.outStream, fmt.Sprintf("gdp %s done(dry-run mode).", subCommand))
 		return ExitSuccess
 	}
 
 	// execution
 	if subCommand == CommandDeploy {
-		if !IsSafetyHour() {
-			fmt.Fprintln(cli.outStream, "It's past the regular time. Is this a hot-fix release?")
-			fmt.Fprint(cli.outStream, "> ")
-			if !yesOrNo(cli) {
-				return ExitError
-			}
+		if !confirmSafetyHour(cli) {
+			return ExitError
 		}
 
+		if err := cli.bumpVersion(tag, changes, opts); err != nil {
+			printError(cli.errStream, fmt.Sprintf("Bump execution error: %s.", err.Error()))
+			return ExitError
+		}
 		if err := cli.gdp.Deploy(tag); err != nil {
 			printError(cli.errStream, fmt.Sprintf("Deploy execution error: %s.", err.Error()))
+			cli.undoVersionCommit(changes)
 			return ExitError
 		}
+		// the version commit is pushed after the tag not to leave it in the branch when deploy failed.
+		if len(changes) > 0 {
+			if err := cli.gdp.PushBranch(); err != nil {
+				printError(cli.errStream, fmt.Sprintf("Push execution error: %s. The tag is pushed, so push the version commit by git push origin HEAD.", err.Error()))
+				return ExitError
+			}
+		}
+		// the deployment refers to the pushed tag, so it is created after the tag exists in remote.
+		cli.recordDeployment(tag, opts.environment)
 	} else {
-		if err := cli.gdp.Publish(tag, note); err != nil {
-			printError(cli.errStream, fmt.Sprintf("Publish execution error: %s.", err.Error()))
-			return ExitError
+		if existing != nil {
+			if err := cli.gdp.UpdateRelease(tag, note); err != nil {
+				printError(cli.errStream, fmt.Sprintf("Update execution error: %s.", err.Error()))
+				return ExitError
+			}
+		} else {
+			if err := cli.gdp.Publish(tag, note, opts.publish); err != nil {
+				printError(cli.errStream, fmt.Sprintf("Publish execution error: %s.", err.Error()))
+				return ExitError
+			}
+		}
+
+		if len(assetFiles) > 0 {
+			if err := cli.gdp.UploadAssets(tag, assetFiles); err != nil {
+				printError(cli.errStream, fmt.Sprintf("Upload execution error: %s.", err.Error()))
+				return ExitError
+			}
+			fmt.Fprintf(cli.outStream, "Uploaded %d assets.\n", len(assetFiles))
 		}
 	}
 
 	printSuccess(cli.outStream, fmt.Sprintf("gdp %s done.", subCommand))
-	message := "Do not be satisfied with 'released', let's face user's feedback in sincerity!"
-	printSuccess(cli.outStream, message)
+	if subCommand == CommandPublish {
+		cli.annotate(tag, commits, opts)
+		cli.updateMilestones(tag, opts)
+	}
+	cli.notify(opts.notifiers, Notification{Command: subCommand, Tag: tag, Note: note}, fromTag)
+	printWatchword(cli.outStream)
 
 	return ExitSuccess
 }
 
-func printSuccess(w io.Writer, message string, args ...interface{}) {
-	message = fmt.Sprintf("[green]%s[reset]", message)
-	fmt.Fprintln(w, colorstring.Color(fmt.Sprintf(message, args...)))
+// uploadAssets uploads the assets to the existing release whose note is not changed. The same-named assets are replaced.
+func (cli *CLI) uploadAssets(tag string, assetFiles []string, opts options) int {
+	if len(assetFiles) == 0 {
+		return ExitSuccess
+	}
+	if opts.dryRun {
+		printSuccess(cli.outStream, fmt.Sprintf("gdp %s done(dry-run mode).", CommandPublish))
+		return ExitSuccess
+	}
+
+	if err := cli.gdp.UploadAssets(tag, assetFiles); err != nil {
+		printError(cli.errStream, fmt.Sprintf("Upload execution error: %s.", err.Error()))
+		return ExitError
+	}
+	fmt.Fprintf(cli.outStream, "Uploaded %d assets.\n", len(assetFiles))
+
+	return ExitSuccess
 }
 
-func printError(w io.Writer, message string, args ...interface{}) {
-	message = fmt.Sprintf("[red]%s[reset]", message)
-	fmt.Fprintln(w, colorstring.Color(fmt.Sprintf(message, args...)))
+// Waiting for the pushed tag to be visible in remote(origin) repository.
+var (
+	tagWaitAttempts = 10
+	tagWaitInterval = 3 * time.Second
+)
+
+// step is the step of release.
+type step struct {
+	name string
+	run  func() error
+	// rollback undoes the step and describes what was rolled back. nil means nothing to roll back.
+	rollback func() (string, error)
+	// irreversible stops rolling back the steps before it(e.g. the published release).
+	irreversible bool
 }
 
-func validate(cli *CLI, subCommand string, tag string) bool {
-	if subCommand == CommandDeploy {
-		if !cli.gdp.IsMasterOrMainBranch() {
-			printError(cli.errStream, "Branch is not master or main.")
-			return false
-		}
-		if cli.gdp.IsExistTagInLocal(tag) {
-			printError(cli.errStream, "Tag is already exist in local.")
-			return false
+// release invokes deploy and publish's process with the same release note.
+func (cli *CLI) release(opts options) int {
+	tag := opts.tag
+
+	// validation of both phases
+	if !opts.force && !validate(cli, CommandDeploy, tag, opts) {
+		return ExitError
+	}
+	if !opts.force && cli.gdp.IsExistTagInRemote(tag) {
+		printError(cli.errStream, "Tag is already exist in remote.")
+		return ExitError
+	}
+
+	fromTag := cli.fromTag(tag, "HEAD", opts)
+	note, commits, ok := cli.releaseNote(tag, fromTag, "HEAD", opts)
+	if !ok {
+		return ExitError
+	}
+
+	changes, ok := cli.versionFileChanges(tag, opts)
+	if !ok {
+		return ExitError
+	}
+
+	assetFiles, cleanup, ok := cli.prepareAssets(opts.assets)
+	if !ok {
+		return ExitError
+	}
+	defer cleanup()
+
+	if opts.dryRun {
+		printSuccess(cli.outStream, fmt.Sprintf("gdp %s done(dry-run mode).", CommandRelease))
+		return ExitSuccess
+	}
+
+	if !confirmSafetyHour(cli) {
+		return ExitError
+	}
+
+	steps := []step{}
+	if len(changes) > 0 {
+		// the version commit is not pushed yet, so it is removed like deploy unless the pushed tag is kept on it.
+		steps = append(steps, step{name: "bump", run: func() error {
+			return cli.bumpVersion(tag, changes, opts)
+		}, rollback: func() (string, error) {
+			if err := cli.gdp.UndoCommit(); err != nil {
+				return "", err
+			}
+			return "removed the version commit", nil
+		}})
+	}
+	pushed := len(changes) == 0
+	for _, s := range cli.releaseSteps(tag, note, assetFiles, opts, func() error {
+		return cli.gdp.Deploy(tag)
+	}) {
+		steps = append(steps, s)
+		// the version commit is pushed after the first irreversible step(the kept tag or the published release)
+		// not to leave it in the branch when the release is rolled back.
+		if s.irreversible && !pushed {
+			steps = append(steps, step{name: "push", run: cli.gdp.PushBranch})
+			pushed = true
 		}
-	} else {
-		if !cli.gdp.IsExistTagInRemote(tag) {
-			printError(cli.errStream, "Tag is not exist in remote.")
-			return false
+	}
+
+	if !cli.runSteps(steps) {
+		printError(cli.errStream, fmt.Sprintf("gdp %s failed.", CommandRelease))
+		return ExitError
+	}
+
+	printSuccess(cli.outStream, fmt.Sprintf("gdp %s done.", CommandRelease))
+	cli.annotate(tag, commits, opts)
+	cli.updateMilestones(tag, opts)
+	cli.notify(opts.notifiers, Notification{Command: CommandRelease, Tag: tag, Note: note}, fromTag)
+	printWatchword(cli.outStream)
+
+	return ExitSuccess
+}
+
+// releaseSteps returns the steps deploying the tag by deploy, waiting for it in remote(origin) repository,
+// publishing it with the note and uploading the assets.
+func (cli *CLI) releaseSteps(tag string, note string, assetFiles []string, opts options, deploy func() error) []step {
+	// the pushed tag is kept without --rollback, so the steps before it are not rolled back either.
+	deployStep := step{name: "deploy", run: deploy, irreversible: !opts.rollback}
+	if opts.rollback {
+		deployStep.rollback = func() (string, error) {
+			if err := cli.gdp.DeleteTag(tag, true); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("deleted remote and local tag %s", tag), nil
 		}
 	}
 
-	return true
+	steps := []step{
+		deployStep,
+		{name: "wait", run: func() error {
+			for i := 0; i < tagWaitAttempts; i++ {
+				if cli.gdp.IsExistTagInRemote(tag) {
+					return nil
+				}
+				time.Sleep(tagWaitInterval)
+			}
+			return fmt.Errorf("tag %s is not found in remote after %d attempts", tag, tagWaitAttempts)
+		}},
+		{name: "publish", irreversible: true, run: func() error {
+			return cli.gdp.Publish(tag, note, opts.publish)
+		}},
+	}
+	if len(assetFiles) > 0 {
+		steps = append(steps, step{name: "upload", run: func() error {
+			return cli.gdp.UploadAssets(tag, assetFiles)
+		}})
+	}
+
+	return steps
 }
 
-var now = time.Now
+// prepare creates the release pull request having the changelog and the version files of the tag.
+func (cli *CLI) prepare(opts options) int {
+	tag := opts.tag
+
+	if !opts.force && !validate(cli, CommandDeploy, tag, opts) {
+		return ExitError
+	}
+
+	fromTag := cli.fromTag(tag, "HEAD", opts)
+	note, _, ok := cli.releaseNote(tag, fromTag, "HEAD", opts)
+	if !ok {
+		return ExitError
+	}
+
+	changelog := cli.config.ReleasePR.Changelog
+	if changelog == "" {
+		changelog = DefaultChangelog
+	}
+	change, err := ChangelogChange(changelog, note)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Updating changelog error: %s.", err.Error()))
+		return ExitError
+	}
+	changes, err := VersionFileChanges(opts.versionFiles, tag, VersionOf(opts.scheme, tag))
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Updating version files error: %s.", err.Error()))
+		return ExitError
+	}
+	changes = append([]FileChange{change}, changes...)
+	cli.showFileChanges(changes)
+
+	// the branch and the pull request of the previous run(e.g. creating the pull request failed) are not overwritten.
+	branch := ReleaseBranch(tag)
+	pr, err := cli.gdp.GetPullRequest(branch)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting pull request error: %s.", err.Error()))
+		return ExitError
+	}
+	if pr != nil {
+		printError(cli.errStream, fmt.Sprintf("Release pull request #%d(%s) of %s already exists: %s", pr.Number, pr.State, branch, pr.HTMLURL))
+		return ExitError
+	}
+	if cli.gdp.IsExistBranch(branch) {
+		printError(cli.errStream, fmt.Sprintf("Release branch %s already exists. Open the pull request of it, or delete it from local and remote repository to prepare again.", branch))
+		return ExitError
+	}
+
+	if opts.dryRun {
+		printSuccess(cli.outStream, fmt.Sprintf("gdp %s done(dry-run mode).", CommandPrepare))
+		return ExitSuccess
+	}
+
+	// execution
+	base, err := cli.gdp.PushReleaseBranch(branch, changes, cli.versionCommitMessage(tag, opts))
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Prepare execution error: %s.", err.Error()))
+		return ExitError
+	}
+	title, body := SplitNote(note)
+	pr, err = cli.gdp.CreatePullRequest(base, branch, title, body)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Creating pull request error: %s.", err.Error()))
+		return ExitError
+	}
+	fmt.Fprintf(cli.outStream, "Created the release pull request #%d: %s\n", pr.Number, pr.HTMLURL)
+
+	printSuccess(cli.outStream, fmt.Sprintf("gdp %s done. Run gdp %s -t %s after the pull request is merged.", CommandPrepare, CommandFinalize, tag))
+
+	return ExitSuccess
+}
+
+// finalize adds the tag to the merge commit of the release pull request and publishes it with the reviewed pull request's body.
+func (cli *CLI) finalize(opts options) int {
+	tag := opts.tag
+
+	if !opts.force && !validate(cli, CommandFinalize, tag, opts) {
+		return ExitError
+	}
+
+	pr, err := cli.gdp.GetPullRequest(ReleaseBranch(tag))
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting pull request error: %s.", err.Error()))
+		return ExitError
+	}
+	if pr == nil {
+		printError(cli.errStream, fmt.Sprintf("Release pull request of %s is not found. Run gdp %s first.", ReleaseBranch(tag), CommandPrepare))
+		return ExitError
+	}
+	if !pr.Merged() {
+		printError(cli.errStream, fmt.Sprintf("Release pull request #%d is not merged.", pr.Number))
+		return ExitError
+	}
+
+	note := pr.Message()
+	fmt.Fprintf(cli.outStream, "The release note of #%d is as follows.\n", pr.Number)
+	fmt.Fprintln(cli.outStream, "====================================")
+	fmt.Fprintln(cli.outStream, note)
+	fmt.Fprintln(cli.outStream, "====================================")
+
+	assetFiles, cleanup, ok := cli.prepareAssets(opts.assets)
+	if !ok {
+		return ExitError
+	}
+	defer cleanup()
+
+	if opts.dryRun {
+		printSuccess(cli.outStream, fmt.Sprintf("gdp %s done(dry-run mode).", CommandFinalize))
+		return ExitSuccess
+	}
+
+	if !confirmSafetyHour(cli) {
+		return ExitError
+	}
+
+	steps := cli.releaseSteps(tag, note, assetFiles, opts, func() error {
+		return cli.gdp.DeployCommit(tag, pr.MergeCommitSHA)
+	})
+	if !cli.runSteps(steps) {
+		printError(cli.errStream, fmt.Sprintf("gdp %s failed.", CommandFinalize))
+		return ExitError
+	}
+
+	printSuccess(cli.outStream, fmt.Sprintf("gdp %s done.", CommandFinalize))
+	fromTag := cli.fromTag(tag, pr.MergeCommitSHA, opts)
+	if opts.annotate {
+		commits, err := cli.gdp.GetCommitList(fromTag, pr.MergeCommitSHA, opts.strategy, opts.component.Paths)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting merge commit error: %s.", err.Error()))
+		} else {
+			cli.annotate(tag, commits, opts)
+		}
+	}
+	cli.updateMilestones(tag, opts)
+	cli.notify(opts.notifiers, Notification{Command: CommandFinalize, Tag: tag, Note: note}, fromTag)
+	printWatchword(cli.outStream)
+
+	return ExitSuccess
+}
+
+// promote adds the tag of the destination environment to the commit of the source environment's tag.
+// The release note is generated since the previous tag of the destination environment.
+func (cli *CLI) promote(opts options) int {
+	tags, err := cli.gdp.ListTags("")
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting tags error: %s.", err.Error()))
+		return ExitError
+	}
+
+	var source Tag
+	found := false
+	if opts.tag != "" {
+		for _, t := range tags {
+			if t.Name == opts.tag && opts.from.Owns(t.Name) {
+				source, found = t, true
+				break
+			}
+		}
+	} else {
+		source, found = LatestEnvironmentTag(opts.from.Scheme(opts.scheme), opts.from, tags)
+	}
+	if !found {
+		printError(cli.errStream, fmt.Sprintf("Tag of %s is not found.", opts.from.Name))
+		return ExitError
+	}
+
+	tag := opts.to.PromotedTag(source.Name, opts.from)
+	fmt.Fprintf(cli.outStream, "Promote %s(%s) to %s(%s).\n", source.Name, opts.from.Name, tag, opts.to.Name)
+
+	if !opts.force && !passes(cli, promoteValidations(cli, source, tag, tags, opts)) {
+		return ExitError
+	}
+
+	// the previous tag is searched by the destination environment's scheme, so the source environment's tags are ignored.
+	opts.scheme = opts.to.Scheme(opts.scheme)
+	fromTag := cli.fromTag(tag, source.Commit, opts)
+	note, _, ok := cli.releaseNote(tag, fromTag, source.Commit, opts)
+	if !ok {
+		return ExitError
+	}
+
+	if opts.dryRun {
+		printSuccess(cli.outStream, fmt.Sprintf("gdp %s done(dry-run mode).", CommandPromote))
+		return ExitSuccess
+	}
+
+	if !confirmSafetyHour(cli) {
+		return ExitError
+	}
+	if err := cli.gdp.DeployCommit(tag, source.Commit); err != nil {
+		printError(cli.errStream, fmt.Sprintf("Promote execution error: %s.", err.Error()))
+		return ExitError
+	}
+
+	printSuccess(cli.outStream, fmt.Sprintf("gdp %s done.", CommandPromote))
+	cli.notify(opts.notifiers, Notification{Command: CommandPromote, Tag: tag, Note: note}, fromTag)
+	printWatchword(cli.outStream)
+
+	return ExitSuccess
+}
+
+// runSteps runs the steps in order until one fails, and prints the summary.
+// When a step failed, the done steps are rolled back in reverse order until the irreversible step.
+func (cli *CLI) runSteps(steps []step) bool {
+	results := make([]string, len(steps))
+	failed := -1
+	for i, s := range steps {
+		if failed >= 0 {
+			results[i] = "[skipped]"
+			continue
+		}
+
+		if err := s.run(); err != nil {
+			printError(cli.errStream, fmt.Sprintf("%s step error: %s.", s.name, err.Error()))
+			results[i] = "[failed]"
+			failed = i
+			continue
+		}
+		results[i] = "[done]"
+	}
+
+	for i := failed - 1; failed >= 0 && i >= 0 && !steps[i].irreversible; i-- {
+		if steps[i].rollback == nil {
+			continue
+		}
+
+		rolledBack, err := steps[i].rollback()
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("%s step rollback error: %s.", steps[i].name, err.Error()))
+			results[i] = "[done](rollback failed)"
+			break
+		}
+		fmt.Fprintf(cli.outStream, "Rolled back %s step: %s.\n", steps[i].name, rolledBack)
+		results[i] = "[rolled back]"
+	}
+
+	fmt.Fprintln(cli.outStream, "Summary:")
+	for i, s := range steps {
+		fmt.Fprintf(cli.outStream, "  %-13s %s\n", results[i], s.name)
+	}
+
+	return failed < 0
+}
+
+// versionFileChanges computes the changes of the version files to the tag and shows them.
+func (cli *CLI) versionFileChanges(tag string, opts options) ([]FileChange, bool) {
+	changes, err := VersionFileChanges(opts.versionFiles, tag, VersionOf(opts.scheme, tag))
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Updating version files error: %s.", err.Error()))
+		return nil, false
+	}
+	if len(changes) == 0 {
+		return changes, true
+	}
+
+	cli.showFileChanges(changes)
+
+	return changes, true
+}
+
+// showFileChanges shows the differences of the files.
+func (cli *CLI) showFileChanges(changes []FileChange) {
+	diffs := []string{}
+	for _, c := range changes {
+		diffs = append(diffs, c.Diff())
+	}
+	fmt.Fprintln(cli.outStream, "The files are changed as follows.")
+	fmt.Fprintln(cli.outStream, "====================================")
+	fmt.Fprintln(cli.outStream, strings.Join(diffs, "\n"))
+	fmt.Fprintln(cli.outStream, "====================================")
+}
+
+// versionCommitMessage returns the message of the commit updating the version files of the tag.
+func (cli *CLI) versionCommitMessage(tag string, opts options) string {
+	message := cli.config.VersionFiles.Message
+	if message == "" {
+		message = DefaultVersionCommitMessage
+	}
+
+	return strings.NewReplacer("{tag}", tag, "{version}", VersionOf(opts.scheme, tag)).Replace(message)
+}
+
+// bumpVersion writes the changes of the version files and commits them, so that the tag is added to the commit.
+// The files are restored when they are not committed.
+func (cli *CLI) bumpVersion(tag string, changes []FileChange, opts options) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	files := []string{}
+	for _, c := range changes {
+		files = append(files, c.Path)
+	}
+	err := WriteFileChanges(changes)
+	if err == nil {
+		err = cli.gdp.CommitFiles(files, cli.versionCommitMessage(tag, opts))
+	}
+	if err != nil {
+		if revertErr := RevertFileChanges(changes); revertErr != nil {
+			return fmt.Errorf("%s(restoring the version files failed: %s)", err.Error(), revertErr.Error())
+		}
+		return err
+	}
+	fmt.Fprintf(cli.outStream, "Committed %d version files.\n", len(files))
+
+	return nil
+}
+
+// undoVersionCommit removes the version commit which is not pushed when deploy failed.
+func (cli *CLI) undoVersionCommit(changes []FileChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	if err := cli.gdp.UndoCommit(); err != nil {
+		printError(cli.errStream, fmt.Sprintf("Undo version commit error: %s.", err.Error()))
+		return
+	}
+	fmt.Fprintln(cli.outStream, "Rolled back the version commit.")
+}
+
+// recordDeployment creates the successful deployment of the pushed tag to the environment in GitHub.
+// Empty environment means no deployment. Failures are reported but do not fail the deploy.
+func (cli *CLI) recordDeployment(tag string, environment string) {
+	if environment == "" {
+		return
+	}
+
+	id, err := cli.gdp.CreateDeployment(tag, environment, fmt.Sprintf("gdp deploy %s", tag))
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Deployment execution error: %s.", err.Error()))
+		return
+	}
+	if err := cli.gdp.SetDeploymentStatus(id, DeploymentSuccess); err != nil {
+		printError(cli.errStream, fmt.Sprintf("Deployment status error: %s.", err.Error()))
+		return
+	}
+	fmt.Fprintf(cli.outStream, "Created the deployment %d of %s to %s.\n", id, tag, environment)
+}
+
+// currentDeployments gets the latest successful deployment of each environment in GitHub.
+func (cli *CLI) currentDeployments() (map[string]Deployment, error) {
+	deployments, err := cli.gdp.ListDeployments()
+	if err != nil {
+		return nil, err
+	}
+
+	return CurrentDeployments(deployments, cli.gdp.GetDeploymentState)
+}
+
+// latestTag resolves the latest tag of the component by git describe, or by the tag scheme when latest_tag.by is version.
+func (cli *CLI) latestTag(opts options) (string, error) {
+	if cli.config.LatestTag.By != LatestTagByVersion {
+		// the whole repository's latest tag is not any component's tag.
+		excludes := []string{}
+		if opts.component.Name == "" {
+			excludes = componentTagPatterns(cli.config.Components)
+		}
+		return cli.gdp.GetLatestTag(opts.component.Prefix(), excludes), nil
+	}
+
+	mergedInto := ""
+	if cli.config.LatestTag.Reachable {
+		mergedInto = "HEAD"
+	}
+	tags, err := cli.gdp.ListTags(mergedInto)
+	if err != nil {
+		return "", err
+	}
+
+	// the tags of the components are not the repository's.
+	names := []string{}
+	for _, t := range tags {
+		if !opts.component.Owns(t.Name) || (opts.component.Name == "" && isComponentTag(t.Name, cli.config.Components)) {
+			continue
+		}
+		names = append(names, t.Name)
+	}
+	if sorted := SortTags(opts.scheme, names); len(sorted) > 0 {
+		return sorted[0], nil
+	}
+
+	return "", nil // No Tag
+}
+
+// fromTag resolves the start of the release note's range. --since takes precedence over the previous tag.
+func (cli *CLI) fromTag(tag string, toTag string, opts options) string {
+	if opts.since != "" {
+		return opts.since
+	}
+
+	return cli.gdp.GetPreviousTag(tag, toTag, opts.scheme)
+}
+
+// releaseNote generates and shows the release note from fromTag to toTag.
+// The commits of the release note are returned with it.
+func (cli *CLI) releaseNote(tag string, fromTag string, toTag string, opts options) (string, []Commit, bool) {
+	commits, err := cli.gdp.GetCommitList(fromTag, toTag, opts.strategy, opts.component.Paths)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting merge commit error: %s.", err.Error()))
+		return "", nil, false
+	}
+	if opts.milestone {
+		commits, err = cli.milestoneCommits(tag, commits, opts)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting milestone error: %s.", err.Error()))
+			return "", nil, false
+		}
+	}
+
+	releaseNote := ReleaseNote{Tag: tag, Commits: LinkIssues(commits, opts.trackers)}
+	if cli.config.Header.Compare || cli.config.Header.Stats {
+		header, err := cli.header(tag, fromTag, toTag)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting header error: %s.", err.Error()))
+			return "", nil, false
+		}
+		releaseNote.Header = header
+	}
+	issues := ExtractIssues(commits, opts.trackers)
+	if len(issues) > 0 {
+		releaseNote.Sections = append(releaseNote.Sections, FormatIssues(issues))
+	}
+	if cli.config.Contributors {
+		past, err := cli.gdp.GetPreviousContributors(fromTag)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting contributors error: %s.", err.Error()))
+			return "", nil, false
+		}
+		releaseNote.Sections = append(releaseNote.Sections, FormatContributors(GetContributors(commits, past)))
+	}
+
+	note := releaseNote.String()
+	fmt.Fprintln(cli.outStream, "The release note is as follows.")
+	fmt.Fprintln(cli.outStream, "====================================")
+	fmt.Fprintln(cli.outStream, note)
+	fmt.Fprintln(cli.outStream, "====================================")
+
+	if opts.exportIssues != "" {
+		if err := ExportIssues(opts.exportIssues, tag, issues); err != nil {
+			printError(cli.errStream, fmt.Sprintf("Exporting issues error: %s.", err.Error()))
+			return "", nil, false
+		}
+	}
+
+	return note, commits, true
+}
+
+// list shows the release history of tags of the tag scheme.
+func (cli *CLI) list(opts options) int {
+	var since time.Time
+	if opts.sinceDate != "" {
+		var err error
+		since, err = time.ParseInLocation("2006-01-02", opts.sinceDate, time.Local)
+		if err != nil {
+			printError(cli.errStream, "Invalid option: --since-date must be YYYY-MM-DD format.")
+			return ExitError
+		}
+	}
+
+	tags, err := cli.gdp.ListTags("")
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting tags error: %s.", err.Error()))
+		return ExitError
+	}
+
+	supported := []Tag{}
+	for _, t := range tags {
+		if opts.scheme.Validate(t.Name) == nil && opts.component.Owns(t.Name) {
+			supported = append(supported, t)
+		}
+	}
+
+	releases, err := cli.gdp.ListReleases()
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting releases error: %s.", err.Error()))
+		return ExitError
+	}
+	published := map[string]bool{}
+	for _, r := range releases {
+		published[r.TagName] = !r.Draft
+	}
+
+	histories := []ReleaseHistory{}
+	for i, t := range supported {
+		if (opts.limit > 0 && len(histories) >= opts.limit) || t.Date.Before(since) {
+			break
+		}
+
+		names := []string{}
+		for _, older := range supported[i+1:] {
+			names = append(names, older.Name)
+		}
+		fromTag := previousTag(opts.scheme, t.Name, names)
+		commits, err := cli.gdp.GetCommitList(fromTag, t.Name, opts.strategy, opts.component.Paths)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting merge commit error: %s.", err.Error()))
+			return ExitError
+		}
+
+		histories = append(histories, ReleaseHistory{Tag: t, PullRequests: len(PullRequestNumbers(commits)), Published: published[t.Name]})
+	}
+
+	// the deployments are optional, so failures are warned and the deployed environments are not shown.
+	var current map[string]Deployment
+	if len(histories) > 0 {
+		current, err = cli.currentDeployments()
+		if err != nil {
+			fmt.Fprintf(cli.errStream, "Warning: the deployed environments are not shown: %s.\n", err.Error())
+		}
+		for i := range histories {
+			histories[i].Environments = DeployedEnvironments(current, histories[i].Tag)
+		}
+	}
+
+	if opts.json {
+		b, err := json.MarshalIndent(histories, "", "  ")
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Formatting error: %s.", err.Error()))
+			return ExitError
+		}
+		fmt.Fprintln(cli.outStream, string(b))
+		return ExitSuccess
+	}
+
+	// the environments are shown only when the deployments exist.
+	w := tabwriter.NewWriter(cli.outStream, 0, 0, 2, ' ', 0)
+	header := "TAG\tDATE\tTAGGER\tCOMMIT\tPRS\tPUBLISHED"
+	if len(current) > 0 {
+		header = header + "\tDEPLOYED"
+	}
+	fmt.Fprintln(w, header)
+	for _, h := range histories {
+		commit := h.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		line := fmt.Sprintf("%s\t%s\t%s\t%s\t%d\t%s", h.Name, h.Date.Format("2006-01-02 15:04"), h.Tagger, commit, h.PullRequests, yesNo(h.Published))
+		if len(current) > 0 {
+			deployed := strings.Join(h.Environments, ",")
+			if deployed == "" {
+				deployed = "-"
+			}
+			line = line + "\t" + deployed
+		}
+		fmt.Fprintln(w, line)
+	}
+	w.Flush()
+
+	return ExitSuccess
+}
+
+// diff shows the release note between any two refs.
+func (cli *CLI) diff(opts options, fromRef string, toRef string) int {
+	boundaries := []string{fromRef, toRef}
+	if opts.group {
+		tags, err := cli.gdp.GetTagsBetween(fromRef, toRef)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting tags error: %s.", err.Error()))
+			return ExitError
+		}
+		boundaries = []string{fromRef}
+		for _, tag := range tags {
+			if opts.scheme.Validate(tag) == nil && opts.component.Owns(tag) {
+				boundaries = append(boundaries, tag)
+			}
+		}
+		boundaries = append(boundaries, toRef)
+	}
+
+	// newest group first
+	groups := []ReleaseNoteGroup{}
+	all := []Commit{}
+	for i := len(boundaries) - 1; i > 0; i-- {
+		commits, err := cli.gdp.GetCommitList(boundaries[i-1], boundaries[i], opts.strategy, opts.component.Paths)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Getting merge commit error: %s.", err.Error()))
+			return ExitError
+		}
+		commits = UniqueCommits(commits, all)
+		all = append(all, commits...)
+		groups = append(groups, ReleaseNoteGroup{Tag: boundaries[i], Commits: LinkIssues(commits, opts.trackers)})
+	}
+
+	releaseNote := ReleaseNote{Tag: fromRef + "..." + toRef, Commits: LinkIssues(all, opts.trackers)}
+	if opts.group {
+		releaseNote.Groups = groups
+	}
+	if cli.config.Header.Compare {
+		if url, err := cli.compareURL(fromRef, toRef); err == nil {
+			releaseNote.Header = append(releaseNote.Header, "**Full Changelog**: "+url)
+		}
+	}
+	if issues := ExtractIssues(all, opts.trackers); len(issues) > 0 {
+		releaseNote.Sections = append(releaseNote.Sections, FormatIssues(issues))
+	}
+
+	fmt.Fprintln(cli.outStream, "The release note is as follows.")
+	fmt.Fprintln(cli.outStream, "====================================")
+	fmt.Fprintln(cli.outStream, releaseNote.String())
+	fmt.Fprintln(cli.outStream, "====================================")
+
+	return ExitSuccess
+}
+
+// status shows what the next deploy would ship without changing anything.
+func (cli *CLI) status(opts options) int {
+	latestTag, err := cli.latestTag(opts)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting latest tag error: %s.", err.Error()))
+		return ExitError
+	}
+	nextTag, err := opts.component.NextVersion(latestTag, opts.scheme)
+
+	w := tabwriter.NewWriter(cli.outStream, 0, 0, 2, ' ', 0)
+	if latestTag == "" {
+		fmt.Fprintln(w, "Latest tag:\t(none)")
+	} else {
+		fmt.Fprintf(w, "Latest tag:\t%s\n", latestTag)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "Next tag:\t(error: %s)\n", err.Error())
+	} else {
+		fmt.Fprintf(w, "Next tag:\t%s\n", nextTag)
+	}
+	fmt.Fprintf(w, "Branch:\t%s\n", okOrNG(cli.gdp.IsMasterOrMainBranch(), "master or main"))
+	fmt.Fprintf(w, "Safety hour:\t%s\n", okOrNG(IsSafetyHour(), fmt.Sprintf("%02d:00-%02d:00", SafetyHourStart, SafetyHourEnd)))
+	w.Flush()
+
+	if err == nil {
+		failures := []string{}
+		for _, v := range validations(cli, CommandDeploy, nextTag, opts) {
+			if !v.ok() {
+				failures = append(failures, v.describe())
+			}
+		}
+		if len(failures) == 0 {
+			fmt.Fprintln(cli.outStream, "Validations: ok")
+		} else {
+			fmt.Fprintln(cli.outStream, "Validations: the following would fail")
+			for _, f := range failures {
+				fmt.Fprintf(cli.outStream, "  - %s\n", f)
+			}
+		}
+	}
+
+	// the latest tag may be on HEAD just after deploy, so it is the start of the range instead of the previous tag of HEAD.
+	fromTag := latestTag
+	if opts.since != "" {
+		fromTag = opts.since
+	}
+	commits, err := cli.gdp.GetCommitList(fromTag, "HEAD", opts.strategy, opts.component.Paths)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Getting merge commit error: %s.", err.Error()))
+		return ExitError
+	}
+	fmt.Fprintf(cli.outStream, "Pending commits(%d):\n", len(commits))
+	if len(commits) > 0 {
+		fmt.Fprintln(cli.outStream, FormatCommitList(commits))
+	}
+
+	return ExitSuccess
+}
+
+func okOrNG(ok bool, detail string) string {
+	if ok {
+		return "ok(" + detail + ")"
+	}
+
+	return "ng(" + detail + ")"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func isSubCommand(name string) bool {
+	return name == CommandDeploy || name == CommandPublish || name == CommandRelease || name == CommandList || name == CommandStatus || name == CommandDiff ||
+		name == CommandPrepare || name == CommandFinalize || name == CommandPromote
+}
+
+func printSuccess(w io.Writer, message string, args ...interface{}) {
+	message = fmt.Sprintf("[green]%s[reset]", message)
+	fmt.Fprintln(w, colorstring.Color(fmt.Sprintf(message, args...)))
+}
+
+func printWatchword(w io.Writer) {
+	message := "Do not be satisfied with 'released', let's face user's feedback in sincerity!"
+	printSuccess(w, message)
+}
+
+func printError(w io.Writer, message string, args ...interface{}) {
+	message = fmt.Sprintf("[red]%s[reset]", message)
+	fmt.Fprintln(w, colorstring.Color(fmt.Sprintf(message, args...)))
+}
+
+// prepareAssets expands the asset patterns and shows them.
+func (cli *CLI) prepareAssets(patterns []string) ([]string, func(), bool) {
+	files, cleanup, err := prepareAssets(patterns)
+	if err != nil {
+		printError(cli.errStream, fmt.Sprintf("Preparing assets error: %s.", err.Error()))
+		return nil, cleanup, false
+	}
+	for _, file := range files {
+		fmt.Fprintf(cli.outStream, "Asset: %s\n", file)
+	}
+
+	return files, cleanup, true
+}
+
+// prepareAssets expands the asset patterns and generates checksums.txt of them in the temporary directory.
+func prepareAssets(patterns []string) ([]string, func(), error) {
+	cleanup := func() {}
+	if len(patterns) == 0 {
+		return nil, cleanup, nil
+	}
+
+	files, err := ExpandAssets(patterns)
+	if err != nil {
+		return nil, cleanup, err
+	}
+
+	dir, err := os.MkdirTemp("", "gdp")
+	if err != nil {
+		return nil, cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	checksums, err := WriteChecksums(files, dir)
+	if err != nil {
+		cleanup()
+		return nil, func() {}, err
+	}
+
+	return append(files, checksums), cleanup, nil
+}
+
+// annotate comments on the pull requests of the commits, adds the label and closes the issues referenced by them.
+// The requests run concurrently, and failures are reported but do not fail the release.
+func (cli *CLI) annotate(tag string, commits []Commit, opts options) {
+	if !opts.annotate {
+		return
+	}
+
+	config := cli.config.Annotate
+	comment := config.Comment
+	if comment == "" {
+		comment = DefaultAnnotateComment
+	}
+	url := ""
+	if strings.Contains(comment, "{url}") {
+		url, _ = cli.releaseURL(tag)
+	}
+	comment = strings.NewReplacer("{tag}", tag, "{url}", url).Replace(comment)
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultAnnotateConcurrency
+	}
+
+	prs := PullRequestNumbers(commits)
+	skipped := make([]bool, len(prs))
+	errs := runConcurrently(len(prs), concurrency, func(i int) error {
+		var err error
+		skipped[i], err = AnnotatePullRequest(cli.gdp, prs[i], comment, config.Label)
+		return err
+	})
+	done, skips, failures := 0, 0, 0
+	for i, err := range errs {
+		switch {
+		case err != nil:
+			failures++
+			printError(cli.errStream, fmt.Sprintf("Annotation(#%d) error: %s.", prs[i], err.Error()))
+		case skipped[i]:
+			skips++
+		default:
+			done++
+		}
+	}
+	fmt.Fprintf(cli.outStream, "Annotated pull requests: %d done, %d skipped(already annotated), %d failed.\n", done, skips, failures)
+
+	if !config.CloseIssues {
+		return
+	}
+	issues := ClosingIssueNumbers(commits)
+	errs = runConcurrently(len(issues), concurrency, func(i int) error {
+		return cli.gdp.CloseIssue(issues[i])
+	})
+	failures = 0
+	for i, err := range errs {
+		if err != nil {
+			failures++
+			printError(cli.errStream, fmt.Sprintf("Closing issue(#%d) error: %s.", issues[i], err.Error()))
+		}
+	}
+	fmt.Fprintf(cli.outStream, "Closed issues: %d done, %d failed.\n", len(issues)-failures, failures)
+}
+
+// milestoneCommits returns the commits of the pull requests of the tag's milestone instead of the commits in the range.
+// The differences between them are warned.
+func (cli *CLI) milestoneCommits(tag string, commits []Commit, opts options) ([]Commit, error) {
+	title := MilestoneTitle(cli.config.Milestone.Title, tag, VersionOf(opts.scheme, tag))
+	milestone, err := cli.gdp.GetMilestone(title)
+	if err != nil {
+		return nil, err
+	}
+	if milestone == nil {
+		return nil, fmt.Errorf("milestone %s is not found", title)
+	}
+	prs, err := cli.gdp.ListMilestonePullRequests(milestone.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	missing, extra := CompareMilestone(commits, prs)
+	for _, n := range missing {
+		fmt.Fprintf(cli.errStream, "Warning: #%d is merged since previous tag but not in the milestone %s.\n", n, title)
+	}
+	for _, n := range extra {
+		fmt.Fprintf(cli.errStream, "Warning: #%d is in the milestone %s but not merged since previous tag.\n", n, title)
+	}
+
+	return MilestoneCommits(prs, commits), nil
+}
+
+// updateMilestones closes the tag's milestone and creates the next version's milestone according to the config.
+// Failures are reported but do not fail the release.
+func (cli *CLI) updateMilestones(tag string, opts options) {
+	config := cli.config.Milestone
+	if config.Close {
+		title := MilestoneTitle(config.Title, tag, VersionOf(opts.scheme, tag))
+		milestone, err := cli.gdp.GetMilestone(title)
+		switch {
+		case err != nil:
+			printError(cli.errStream, fmt.Sprintf("Closing milestone error: %s.", err.Error()))
+		case milestone == nil:
+			fmt.Fprintf(cli.outStream, "Milestone %s is not found.\n", title)
+		case milestone.State != "closed":
+			if err := cli.gdp.CloseMilestone(milestone.Number); err != nil {
+				printError(cli.errStream, fmt.Sprintf("Closing milestone error: %s.", err.Error()))
+			} else {
+				fmt.Fprintf(cli.outStream, "Closed the milestone %s.\n", title)
+			}
+		}
+	}
+
+	if config.CreateNext {
+		next, err := opts.scheme.Next(tag)
+		if err != nil {
+			printError(cli.errStream, fmt.Sprintf("Creating milestone error: %s.", err.Error()))
+			return
+		}
+		title := MilestoneTitle(config.Title, next, VersionOf(opts.scheme, next))
+		milestone, err := cli.gdp.GetMilestone(title)
+		switch {
+		case err != nil:
+			printError(cli.errStream, fmt.Sprintf("Creating milestone error: %s.", err.Error()))
+		case milestone == nil:
+			if err := cli.gdp.CreateMilestone(title); err != nil {
+				printError(cli.errStream, fmt.Sprintf("Creating milestone error: %s.", err.Error()))
+			} else {
+				fmt.Fprintf(cli.outStream, "Created the milestone %s.\n", title)
+			}
+		}
+	}
+}
+
+// notify sends the notification to the notifiers. Failures are reported but do not fail the release.
+func (cli *CLI) notify(notifiers []Notifier, n Notification, fromTag string) {
+	if len(notifiers) == 0 {
+		return
+	}
+
+	if fromTag != "" {
+		if url, err := cli.compareURL(fromTag, n.Tag); err == nil {
+			n.CompareURL = url
+		}
+	}
+
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(n); err != nil {
+			printError(cli.errStream, fmt.Sprintf("Notification(%s) error: %s.", notifier.Name(), err.Error()))
+			continue
+		}
+		fmt.Fprintf(cli.outStream, "Notified to %s.\n", notifier.Name())
+	}
+}
+
+// compareURL creates the URL comparing from the tag to the tag in remote(origin) repository.
+func (cli *CLI) compareURL(fromTag string, toTag string) (string, error) {
+	remote, err := cli.gdp.GetRemoteURL()
+	if err != nil {
+		return "", err
+	}
+	repo, err := ParseRemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+
+	return repo.CompareURL(fromTag, toTag), nil
+}
+
+// releaseURL creates the URL of the release of the tag in remote(origin) repository.
+func (cli *CLI) releaseURL(tag string) (string, error) {
+	remote, err := cli.gdp.GetRemoteURL()
+	if err != nil {
+		return "", err
+	}
+	repo, err := ParseRemoteURL(remote)
+	if err != nil {
+		return "", err
+	}
+
+	return repo.ReleaseURL(tag), nil
+}
+
+// header creates the header of the release note according to the config.
+func (cli *CLI) header(tag string, fromTag string, toTag string) ([]string, error) {
+	stat, err := cli.gdp.GetRangeStat(fromTag, toTag)
+	if err != nil {
+		return nil, err
+	}
+
+	header := []string{}
+	if cli.config.Header.Compare && stat.FromTag != "" {
+		url, err := cli.compareURL(stat.FromTag, tag)
+		if err != nil {
+			return nil, err
+		}
+		header = append(header, "**Full Changelog**: "+url)
+	}
+	if cli.config.Header.Stats {
+		header = append(header, stat.String())
+	}
+
+	return header, nil
+}
+
+func validate(cli *CLI, subCommand string, tag string, opts options) bool {
+	return passes(cli, validations(cli, subCommand, tag, opts))
+}
+
+// passes runs the validations in order and prints the message of the first failed one.
+func passes(cli *CLI, vs []validation) bool {
+	for _, v := range vs {
+		if !v.ok() {
+			printError(cli.errStream, v.describe())
+			return false
+		}
+	}
+
+	return true
+}
+
+// validation is the check before deploy or publish, and the message when it fails.
+type validation struct {
+	ok      func() bool
+	message string
+	// hint describes how to fix it after the message. nil means no hint.
+	hint func() string
+}
+
+// describe returns the message with the hint.
+func (v validation) describe() string {
+	if v.hint == nil {
+		return v.message
+	}
+
+	return v.message + " " + v.hint()
+}
+
+func validations(cli *CLI, subCommand string, tag string, opts options) []validation {
+	if subCommand == CommandDeploy {
+		// the latest tag is resolved by the validation before the ones comparing the tag with it.
+		var latestTag string
+		var latestErr, formatErr error
+		expected := func() string {
+			return fmt.Sprintf("Expected: %s.", orList(nextVersions(opts.component, opts.scheme, latestTag)))
+		}
+
+		return []validation{
+			{cli.gdp.IsMasterOrMainBranch, "Branch is not master or main.", nil},
+			{func() bool { return !cli.gdp.IsExistTagInLocal(tag) }, "Tag is already exist in local.", nil},
+			{func() bool { latestTag, latestErr = cli.latestTag(opts); return latestErr == nil }, "Getting latest tag error:", func() string {
+				return latestErr.Error() + "."
+			}},
+			{func() bool { formatErr = opts.scheme.Validate(tag); return formatErr == nil }, "Tag is invalid format:", func() string {
+				return fmt.Sprintf("%s. %s", formatErr.Error(), expected())
+			}},
+			{func() bool { return cli.gdp.IsValidTagName(tag) }, "Tag is not valid ref name(see git check-ref-format).", nil},
+			{func() bool { return opts.allowDowngrade || IsComparableTag(opts.scheme, tag, latestTag) }, "Tag can not be compared with the latest tag:", func() string {
+				return fmt.Sprintf("%s(the different format or prefix). %s Run with --allow-downgrade to deploy it.", latestTag, expected())
+			}},
+			{func() bool { return opts.allowDowngrade || IsNewerTag(opts.scheme, tag, latestTag) }, "Tag is not greater than the latest tag.", func() string {
+				return fmt.Sprintf("The latest tag is %s. %s Run with --allow-downgrade to deploy it.", latestTag, expected())
+			}},
+		}
+	}
+
+	if subCommand == CommandFinalize {
+		var formatErr error
+
+		return []validation{
+			{func() bool { return !cli.gdp.IsExistTagInLocal(tag) }, "Tag is already exist in local.", nil},
+			{func() bool { return !cli.gdp.IsExistTagInRemote(tag) }, "Tag is already exist in remote.", nil},
+			{func() bool { formatErr = opts.scheme.Validate(tag); return formatErr == nil }, "Tag is invalid format:", func() string {
+				return formatErr.Error() + "."
+			}},
+		}
+	}
+
+	return []validation{
+		{func() bool { return cli.gdp.IsExistTagInRemote(tag) }, "Tag is not exist in remote.", nil},
+	}
+}
+
+// promoteValidations are the checks of the source environment's tag and the promoted tag before promote.
+func promoteValidations(cli *CLI, source Tag, tag string, tags []Tag, opts options) []validation {
+	fromScheme, toScheme := opts.from.Scheme(opts.scheme), opts.to.Scheme(opts.scheme)
+	latest, _ := LatestEnvironmentTag(toScheme, opts.to, tags)
+	var sourceErr, formatErr error
+	var promoted Tag
+
+	return []validation{
+		{func() bool { return cli.gdp.IsExistTagInRemote(source.Name) }, fmt.Sprintf("Tag %s is not exist in remote.", source.Name), nil},
+		{func() bool { sourceErr = fromScheme.Validate(source.Name); return sourceErr == nil }, fmt.Sprintf("Tag %s is invalid format:", source.Name), func() string {
+			return sourceErr.Error() + "."
+		}},
+		{func() bool {
+			var ok bool
+			promoted, ok = PromotedTagOf(opts.to, source.Commit, tags)
+			return !ok
+		}, fmt.Sprintf("Tag %s is already promoted to %s:", source.Name, opts.to.Name), func() string {
+			return promoted.Name + "."
+		}},
+		{func() bool { return !cli.gdp.IsExistTagInLocal(tag) }, "Tag is already exist in local.", nil},
+		{func() bool { return !cli.gdp.IsExistTagInRemote(tag) }, "Tag is already exist in remote.", nil},
+		{func() bool { formatErr = toScheme.Validate(tag); return formatErr == nil }, "Tag is invalid format:", func() string {
+			return formatErr.Error() + "."
+		}},
+		{func() bool { return cli.gdp.IsValidTagName(tag) }, "Tag is not valid ref name(see git check-ref-format).", nil},
+		{func() bool { return opts.allowDowngrade || IsComparableTag(toScheme, tag, latest.Name) }, "Tag can not be compared with the latest tag:", func() string {
+			return fmt.Sprintf("%s of %s(the different format or prefix). Run with --allow-downgrade to promote it.", latest.Name, opts.to.Name)
+		}},
+		{func() bool { return opts.allowDowngrade || IsNewerTag(toScheme, tag, latest.Name) }, "Tag is not greater than the latest tag.", func() string {
+			return fmt.Sprintf("The latest tag of %s is %s. Run with --allow-downgrade to promote it.", opts.to.Name, latest.Name)
+		}},
+	}
+}
+
+// nextVersions gets the candidates of the next tag of the latest tag. SemVer has patch, minor and major versions.
+func nextVersions(component Component, scheme TagScheme, latestTag string) []string {
+	next, err := component.NextVersion(latestTag, scheme)
+	if err != nil {
+		return []string{"(" + err.Error() + ")"}
+	}
+
+	if v, err := scheme.Parse(latestTag); err == nil && v.Scheme == (SemVer{}).Name() && v.Prerelease == "" {
+		return []string{
+			next,
+			fmt.Sprintf("%s%d.%d.0", v.Prefix, v.Numbers[0], v.Numbers[1]+1),
+			fmt.Sprintf("%s%d.0.0", v.Prefix, v.Numbers[0]+1),
+		}
+	}
+
+	return []string{next}
+}
+
+// orList joins the words like "a, b or c".
+func orList(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+
+	return strings.Join(words[:len(words)-1], ", ") + " or " + words[len(words)-1]
+}
+
+var now = time.Now
+
+// confirmSafetyHour asks whether the deploy is a hot-fix release when it's past the regular time.
+func confirmSafetyHour(cli *CLI) bool {
+	if IsSafetyHour() {
+		return true
+	}
+
+	fmt.Fprintln(cli.outStream, "It's past the regular time. Is this a hot-fix release?")
+	fmt.Fprint(cli.outStream, "> ")
+	return yesOrNo(cli)
+}
 
 func IsSafetyHour() bool {
 	return now().Hour() >= SafetyHourStart && now().Hour() < SafetyHourEnd
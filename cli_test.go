@@ -2,8 +2,13 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -151,6 +156,11 @@ func TestRun_InvalidSubCommand(t *testing.T) {
 }
 
 // Tests for deploy
+var fakeCommits = []Commit{
+	{Author: "itosho", Title: "initial commit"},
+	{Author: "itosho", Title: "fix bug"},
+}
+
 type FakeGdpDeploy struct {
 	Gdp
 }
@@ -163,14 +173,20 @@ func (f *FakeGdpDeploy) IsExistTagInLocal(tag string) bool {
 	return false
 }
 
-func (f *FakeGdpDeploy) GetLatestTag() string {
+func (f *FakeGdpDeploy) IsValidTagName(tag string) bool {
+	return true
+}
+
+func (f *FakeGdpDeploy) GetLatestTag(prefix string, excludes []string) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpDeploy) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
 	return "v1.2.3"
 }
 
-func (f *FakeGdpDeploy) GetMergeCommitList(toTag string) (string, error) {
-	list := "- itosho: initial commit\n"
-	list = list + "- itosho: fix bug"
-	return list, nil
+func (f *FakeGdpDeploy) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return fakeCommits, nil
 }
 
 func (f *FakeGdpDeploy) Deploy(tag string) error {
@@ -240,240 +256,431 @@ func TestRun_DeployNoSpecifiedTag(t *testing.T) {
 	}
 }
 
-type FakeGdpDeployForce struct {
-	Gdp
-}
-
-func (f *FakeGdpDeployForce) GetMergeCommitList(toTag string) (string, error) {
-	list := "- itosho: initial commit\n"
-	list = list + "- itosho: fix bug"
-	return list, nil
+type FakeGdpDeployStrategy struct {
+	FakeGdpDeploy
+	strategy CommitStrategy
 }
 
-func (f *FakeGdpDeployForce) Deploy(tag string) error {
-	return nil
+func (f *FakeGdpDeployStrategy) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	f.strategy = strategy
+	return fakeCommits, nil
 }
 
-func TestRun_DeployForce(t *testing.T) {
-	out, err := new(bytes.Buffer), new(bytes.Buffer)
-	cli := &CLI{
-		outStream: out,
-		errStream: err,
-		gdp:       &FakeGdpDeployForce{},
+func TestRun_DeployStrategy(t *testing.T) {
+	type pattern struct {
+		exp    CommitStrategy
+		config string
+		args   string
 	}
-	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
-
-	args := strings.Split("gdp deploy -t v1.2.4 -f", " ")
-	code := cli.Run(args)
-	if code != ExitSuccess {
-		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	patterns := []pattern{
+		{StrategyMerges, "", "gdp deploy -t v1.2.4 -d"},
+		{StrategyFirstParent, "first-parent", "gdp deploy -t v1.2.4 -d"},
+		{StrategyNoMerges, "first-parent", "gdp deploy -t v1.2.4 -d --strategy no-merges"},
 	}
 
-	expected := "gdp deploy done."
-	if !strings.Contains(out.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpDeployStrategy{}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config:    Config{Strategy: p.config},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+		}
+		if fake.strategy != p.exp {
+			t.Errorf("Strategy=%q, Expected=%q, Args=%q", fake.strategy, p.exp, p.args)
+		}
 	}
 }
 
-type FakeGdpDeployNotMasterBranch struct {
-	Gdp
+type FakeGdpDeploySince struct {
+	FakeGdpDeploy
+	fromRef string
 }
 
-func (f *FakeGdpDeployNotMasterBranch) IsMasterOrMainBranch() bool {
-	return false
+func (f *FakeGdpDeploySince) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	f.fromRef = fromRef
+	return fakeCommits, nil
 }
 
-func TestRun_DeployNotMasterBranch(t *testing.T) {
-	out, err := new(bytes.Buffer), new(bytes.Buffer)
-	cli := &CLI{
-		outStream: out,
-		errStream: err,
-		gdp:       &FakeGdpDeployNotMasterBranch{},
+func TestRun_DeploySince(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
 	}
-
-	args := strings.Split("gdp deploy -t v1.2.4", " ")
-	code := cli.Run(args)
-	if code != ExitError {
-		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	patterns := []pattern{
+		{"v1.2.3", "gdp deploy -t v1.2.4 -d"},
+		{"v1.2.0", "gdp deploy -t v1.2.4 -d --since v1.2.0"},
 	}
 
-	expected := "Branch is not master or main."
-	if !strings.Contains(err.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpDeploySince{}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+		}
+		if fake.fromRef != p.exp {
+			t.Errorf("FromRef=%q, Expected=%q, Args=%q", fake.fromRef, p.exp, p.args)
+		}
 	}
 }
 
-type FakeGdpDeployExistTagInLocal struct {
-	Gdp
+type FakeGdpDeployComponent struct {
+	FakeGdpDeploy
+	paths    []string
+	excludes []string
 }
 
-func (f *FakeGdpDeployExistTagInLocal) IsMasterOrMainBranch() bool {
-	return true
+func (f *FakeGdpDeployComponent) GetLatestTag(prefix string, excludes []string) string {
+	f.excludes = excludes
+	return prefix + "v1.2.3"
 }
 
-func (f *FakeGdpDeployExistTagInLocal) IsExistTagInLocal(tag string) bool {
-	return true
+func (f *FakeGdpDeployComponent) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	f.paths = paths
+	return fakeCommits, nil
 }
 
-func TestRun_DeployExistTagInLocal(t *testing.T) {
+func TestRun_DeployComponent(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpDeployComponent{}
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpDeployExistTagInLocal{},
+		gdp:       fake,
+		config:    Config{Components: map[string]ComponentConfig{"api": {Paths: []string{"services/api"}}}},
 	}
 
-	args := strings.Split("gdp deploy -t v1.2.4", " ")
+	args := strings.Split("gdp deploy --component api -d", " ")
 	code := cli.Run(args)
-	if code != ExitError {
-		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
 	}
 
-	expected := "Tag is already exist in local."
-	if !strings.Contains(err.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	expected := "Release api/v1.2.4"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	if !reflect.DeepEqual(fake.paths, []string{"services/api"}) {
+		t.Errorf("Paths=%q, Expected=%q", fake.paths, []string{"services/api"})
 	}
 }
 
-type FakeGdpDeployErrorInGetNextTag struct {
-	Gdp
+func TestRun_DeployExcludesComponentTags(t *testing.T) {
+	type pattern struct {
+		exp      string
+		excludes []string
+		args     string
+	}
+	patterns := []pattern{
+		{"Release v1.2.4", []string{"api/*", "web/*"}, "gdp deploy -d"},
+		{"Release api/v1.2.4", []string{}, "gdp deploy --component api -d"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpDeployComponent{}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config:    Config{Components: map[string]ComponentConfig{"web": {}, "api": {}}},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+		if !reflect.DeepEqual(fake.excludes, p.excludes) {
+			t.Errorf("Excludes=%q, Expected=%q", fake.excludes, p.excludes)
+		}
+	}
 }
 
-func (f *FakeGdpDeployErrorInGetNextTag) IsMasterOrMainBranch() bool {
-	return true
+func TestRun_DeployInvalidComponent(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
+	}
+	patterns := []pattern{
+		{`Invalid option: unknown component "web"(configured: api).`, "gdp deploy --component web"},
+		{"Invalid option: tag must have the component's prefix api/.", "gdp deploy --component api -t v1.2.4"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       &FakeGdpDeploy{},
+			config:    Config{Components: map[string]ComponentConfig{"api": {}}},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+	}
 }
 
-func (f *FakeGdpDeployErrorInGetNextTag) IsExistTagInLocal(tag string) bool {
-	return false
+type FakeGdpDeployVersionFiles struct {
+	FakeGdpDeploy
+	files     []string
+	message   string
+	commitErr error
+	deployErr error
+	calls     []string
 }
 
-func (f *FakeGdpDeployErrorInGetNextTag) GetLatestTag() string {
-	return "v1.2.semantic"
+func (f *FakeGdpDeployVersionFiles) CommitFiles(files []string, message string) error {
+	f.files, f.message = files, message
+	f.calls = append(f.calls, "commit")
+	return f.commitErr
 }
 
-func TestRun_DeployErrorInGetNextTag(t *testing.T) {
+func (f *FakeGdpDeployVersionFiles) UndoCommit() error {
+	f.calls = append(f.calls, "undo")
+	return nil
+}
+
+func (f *FakeGdpDeployVersionFiles) PushBranch() error {
+	f.calls = append(f.calls, "push")
+	return nil
+}
+
+func (f *FakeGdpDeployVersionFiles) Deploy(tag string) error {
+	f.calls = append(f.calls, "deploy")
+	return f.deployErr
+}
+
+func TestRun_DeployVersionFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n", "package.json": "{\n  \"version\": \"1.2.3\"\n}\n"})
+	config := Config{VersionFiles: VersionFilesConfig{
+		Files:   []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}, {Path: filepath.Join(dir, "package.json"), JSON: "version"}},
+		Message: "chore: release {version}",
+	}}
+
+	type pattern struct {
+		exp     string
+		args    string
+		content string
+		message string
+		calls   []string
+	}
+	patterns := []pattern{
+		{"gdp deploy done(dry-run mode).", "gdp deploy -t v1.2.4 -d", "1.2.3\n", "", nil},
+		{"Committed 2 version files.", "gdp deploy -t v1.2.4", "1.2.4\n", "chore: release 1.2.4", []string{"commit", "deploy", "push"}},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpDeployVersionFiles{}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config:    config,
+		}
+		fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+
+		diff := "--- " + filepath.Join(dir, "VERSION") + "\n+++ " + filepath.Join(dir, "VERSION") + "\n- 1.2.3\n+ 1.2.4\n"
+		for _, exp := range []string{diff, "+   \"version\": \"1.2.4\"", p.exp} {
+			if !strings.Contains(out.String(), exp) {
+				t.Errorf("Output=%q, Expected=%q", out.String(), exp)
+			}
+		}
+		b, _ := os.ReadFile(filepath.Join(dir, "VERSION"))
+		if string(b) != p.content {
+			t.Errorf("Output=%q, Expected=%q", string(b), p.content)
+		}
+		if fake.message != p.message {
+			t.Errorf("Output=%q, Expected=%q", fake.message, p.message)
+		}
+		if !reflect.DeepEqual(fake.calls, p.calls) {
+			t.Errorf("Calls=%q, Expected=%q", fake.calls, p.calls)
+		}
+	}
+}
+
+func TestRun_DeployVersionFilesErrorInDeploy(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n"})
+
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpDeployVersionFiles{deployErr: errors.New("rejected")}
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpDeployErrorInGetNextTag{},
+		gdp:       fake,
+		config:    Config{VersionFiles: VersionFilesConfig{Files: []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}}}},
 	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
 
-	args := strings.Split("gdp deploy", " ")
-	code := cli.Run(args)
+	code := cli.Run(strings.Split("gdp deploy -t v1.2.4", " "))
 	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "Getting release tag error"
-	if !strings.Contains(err.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	// the version commit is not pushed to the branch.
+	expected := []string{"commit", "deploy", "undo"}
+	if !reflect.DeepEqual(fake.calls, expected) {
+		t.Errorf("Calls=%q, Expected=%q", fake.calls, expected)
+	}
+	if !strings.Contains(out.String(), "Rolled back the version commit.") {
+		t.Errorf("Output=%q, Expected=%q", out.String(), "Rolled back the version commit.")
 	}
 }
 
-type FakeGdpDeployErrorInGetMergeCommitList struct {
-	Gdp
-}
-
-func (f *FakeGdpDeployErrorInGetMergeCommitList) IsMasterOrMainBranch() bool {
-	return true
-}
-
-func (f *FakeGdpDeployErrorInGetMergeCommitList) IsExistTagInLocal(tag string) bool {
-	return false
-}
-
-func (f *FakeGdpDeployErrorInGetMergeCommitList) GetMergeCommitList(toTag string) (string, error) {
-	return "", errors.New("error occurred")
-}
+func TestRun_DeployVersionFilesErrorInCommit(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n"})
 
-func TestRun_ErrorInGetMergeCommitList(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpDeployVersionFiles{commitErr: errors.New("pre-commit hook failed")}
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpDeployErrorInGetMergeCommitList{},
+		gdp:       fake,
+		config:    Config{VersionFiles: VersionFilesConfig{Files: []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}}}},
 	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
 
-	args := strings.Split("gdp deploy -t v1.2.4", " ")
-	code := cli.Run(args)
+	code := cli.Run(strings.Split("gdp deploy -t v1.2.4", " "))
 	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "Getting merge commit error"
+	expected := "Bump execution error: pre-commit hook failed."
 	if !strings.Contains(err.String(), expected) {
 		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
 	}
+	// the version file is not left modified.
+	b, _ := os.ReadFile(filepath.Join(dir, "VERSION"))
+	if string(b) != "1.2.3\n" {
+		t.Errorf("Output=%q, Expected=%q", string(b), "1.2.3\n")
+	}
+	if !reflect.DeepEqual(fake.calls, []string{"commit"}) {
+		t.Errorf("Calls=%q, Expected=%q", fake.calls, []string{"commit"})
+	}
 }
 
-type FakeGdpDeployErrorInDeploy struct {
-	Gdp
-}
-
-func (f *FakeGdpDeployErrorInDeploy) IsMasterOrMainBranch() bool {
-	return true
-}
-
-func (f *FakeGdpDeployErrorInDeploy) IsExistTagInLocal(tag string) bool {
-	return false
-}
-
-func (f *FakeGdpDeployErrorInDeploy) GetMergeCommitList(toTag string) (string, error) {
-	list := "- itosho: initial commit\n"
-	list = list + "- itosho: fix bug"
-	return list, nil
-}
-
-func (f *FakeGdpDeployErrorInDeploy) Deploy(tag string) error {
-	return errors.New("error occurred")
-}
-
-func TestRun_DeployErrorInDeploy(t *testing.T) {
+func TestRun_DeployInvalidVersionFile(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpDeployErrorInDeploy{},
+		gdp:       &FakeGdpDeploy{},
+		config:    Config{VersionFiles: VersionFilesConfig{Files: []VersionFileConfig{{Path: filepath.Join(t.TempDir(), "VERSION")}}}},
 	}
-	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
 
-	args := strings.Split("gdp deploy -t v1.2.4", " ")
-	code := cli.Run(args)
+	code := cli.Run(strings.Split("gdp deploy -t v1.2.4 -d", " "))
 	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "Deploy execution error"
+	expected := "Updating version files error:"
 	if !strings.Contains(err.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
 	}
 }
 
-// Tests for publish
-type FakeGdpPublish struct {
-	Gdp
+type FakeGdpDeployEnvironment struct {
+	FakeGdpDeployVersionFiles
+	ref         string
+	environment string
+	states      []string
 }
 
-func (f *FakeGdpPublish) IsExistTagInRemote(tag string) bool {
-	return true
+func (f *FakeGdpDeployEnvironment) CreateDeployment(ref string, environment string, description string) (int, error) {
+	f.ref, f.environment = ref, environment
+	f.calls = append(f.calls, "deployment")
+	return 42, nil
 }
 
-func (f *FakeGdpPublish) GetLatestTag() string {
-	return "v1.2.3"
+func (f *FakeGdpDeployEnvironment) SetDeploymentStatus(id int, state string) error {
+	f.states = append(f.states, state)
+	return nil
 }
 
-func (f *FakeGdpPublish) GetMergeCommitList(toTag string) (string, error) {
-	list := "- itosho: initial commit\n"
-	list = list + "- itosho: fix bug"
-	return list, nil
-}
+func TestRun_DeployEnvironment(t *testing.T) {
+	dir := t.TempDir()
 
-func (f *FakeGdpPublish) Publish(tag string, commits string) error {
-	return nil
+	type pattern struct {
+		code      int
+		deployErr error
+		files     []VersionFileConfig
+		calls     []string
+		states    []string
+	}
+	patterns := []pattern{
+		{ExitSuccess, nil, nil, []string{"deploy", "deployment"}, []string{DeploymentSuccess}},
+		{ExitError, errors.New("rejected"), nil, []string{"deploy"}, nil},
+		// the deployment is created after the version commit is pushed with the tag.
+		{ExitSuccess, nil, []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}}, []string{"commit", "deploy", "push", "deployment"}, []string{DeploymentSuccess}},
+		{ExitError, errors.New("rejected"), []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}}, []string{"commit", "deploy", "undo"}, nil},
+	}
+
+	for _, p := range patterns {
+		writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n"})
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpDeployEnvironment{FakeGdpDeployVersionFiles: FakeGdpDeployVersionFiles{deployErr: p.deployErr}}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config:    Config{VersionFiles: VersionFilesConfig{Files: p.files}},
+		}
+		fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+		code := cli.Run(strings.Split("gdp deploy -t v1.2.4 --environment production", " "))
+		if code != p.code {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, p.code, err.String())
+		}
+
+		if !reflect.DeepEqual(fake.calls, p.calls) {
+			t.Errorf("Calls=%q, Expected=%q", fake.calls, p.calls)
+		}
+		if !reflect.DeepEqual(fake.states, p.states) {
+			t.Errorf("States=%q, Expected=%q", fake.states, p.states)
+		}
+		if p.code != ExitSuccess {
+			continue
+		}
+		expected := "Created the deployment 42 of v1.2.4 to production."
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		}
+		if fake.ref != "v1.2.4" || fake.environment != "production" {
+			t.Errorf("Ref=%q, Environment=%q, Expected=%q, %q", fake.ref, fake.environment, "v1.2.4", "production")
+		}
+	}
 }
 
-func TestRun_Publish(t *testing.T) {
+func TestRun_PublishEnvironment(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{
 		outStream: out,
@@ -481,147 +688,1084 @@ func TestRun_Publish(t *testing.T) {
 		gdp:       &FakeGdpPublish{},
 	}
 
-	args := strings.Split("gdp publish -t v1.2.3", " ")
-	code := cli.Run(args)
-	if code != ExitSuccess {
+	code := cli.Run(strings.Split("gdp publish -t v1.2.3 --environment production", " "))
+	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "gdp publish done."
-	if !strings.Contains(out.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	expected := "Invalid option: --environment is available for deploy."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
 	}
 }
 
-func TestRun_PublishDryRun(t *testing.T) {
+func TestRun_DeployCalVer(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpPublish{},
+		gdp:       &FakeGdpDeploy{},
+		config:    Config{CalVer: CalVerConfig{Layout: "YYYY.0M.0D"}},
 	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
 
-	args := strings.Split("gdp publish -t v1.2.3 -dry-run", " ")
+	args := strings.Split("gdp deploy -d", " ")
 	code := cli.Run(args)
-	if code != ExitSuccess {
+	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "gdp publish done(dry-run mode)."
-	if !strings.Contains(out.String(), expected) {
-		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	expected := `Getting release tag error: latest tag "v1.2.3" does not match the CalVer layout "YYYY.0M.0D".`
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
 	}
 }
 
-func TestRun_PublishNoSpecifiedTag(t *testing.T) {
+type FakeGdpDeployCounter struct {
+	FakeGdpDeploy
+}
+
+func (f *FakeGdpDeployCounter) GetLatestTag(prefix string, excludes []string) string {
+	return "release-41"
+}
+
+func TestRun_DeployScheme(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpPublish{},
+		gdp:       &FakeGdpDeployCounter{},
+		config:    Config{Scheme: "counter"},
 	}
 
-	args := strings.Split("gdp publish", " ")
+	args := strings.Split("gdp deploy -d", " ")
 	code := cli.Run(args)
 	if code != ExitSuccess {
-		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
 	}
 
-	expected := "v1.2.3"
+	expected := "Release release-42"
 	if !strings.Contains(out.String(), expected) {
 		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
 	}
 }
 
-type FakeGdpPublishForce struct {
-	Gdp
-}
-
-func (f *FakeGdpPublishForce) GetMergeCommitList(toTag string) (string, error) {
-	list := "- itosho: initial commit\n"
-	list = list + "- itosho: fix bug"
-	return list, nil
+type FakeGdpDeployInvalidTag struct {
+	FakeGdpDeploy
 }
 
-func (f *FakeGdpPublishForce) Publish(tag string, commits string) error {
-	return nil
+func (f *FakeGdpDeployInvalidTag) IsValidTagName(tag string) bool {
+	return !strings.Contains(tag, "..")
 }
 
-func TestRun_PublishForce(t *testing.T) {
-	out, err := new(bytes.Buffer), new(bytes.Buffer)
-	cli := &CLI{
-		outStream: out,
-		errStream: err,
-		gdp:       &FakeGdpPublishForce{},
+func TestRun_DeployInvalidTag(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
+	}
+	patterns := []pattern{
+		{`Tag is invalid format: tag "v1.2" is neither semantic(e.g. v1.2.3) nor date(e.g. 20180525.1) format. Expected: v1.2.4, v1.3.0 or v2.0.0.`, "gdp deploy -t v1.2 -d"},
+		{"Tag is not valid ref name(see git check-ref-format).", "gdp deploy -t hotfix..1/v1.2.4 -d"},
+		{"Tag is not greater than the latest tag. The latest tag is v1.2.3. Expected: v1.2.4, v1.3.0 or v2.0.0. Run with --allow-downgrade to deploy it.", "gdp deploy -t v1.2.3-rc.1 -d"},
+		{"Tag can not be compared with the latest tag: v1.2.3(the different format or prefix). Expected: v1.2.4, v1.3.0 or v2.0.0. Run with --allow-downgrade to deploy it.", "gdp deploy -t 1.0.0 -d"},
+		{"Tag can not be compared with the latest tag: v1.2.3(the different format or prefix). Expected: v1.2.4, v1.3.0 or v2.0.0. Run with --allow-downgrade to deploy it.", "gdp deploy -t 20200101.1 -d"},
 	}
 
-	args := strings.Split("gdp publish -t v1.2.3 -f", " ")
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       &FakeGdpDeployInvalidTag{},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d, Args=%q", code, ExitError, p.args)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+	}
+}
+
+func TestRun_DeployAllowDowngrade(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeploy{},
+	}
+
+	for _, tag := range []string{"v1.2.2", "1.0.0"} {
+		args := strings.Split("gdp deploy -d --allow-downgrade -t "+tag, " ")
+		code := cli.Run(args)
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+	}
+}
+
+type FakeGdpDeployLatestTag struct {
+	FakeGdpDeploy
+	mergedInto string
+}
+
+func (f *FakeGdpDeployLatestTag) ListTags(mergedInto string) ([]Tag, error) {
+	f.mergedInto = mergedInto
+	return []Tag{{Name: "v1.9.0"}, {Name: "api/v2.0.0"}, {Name: "v1.10.0"}, {Name: "v1.8.1"}}, nil
+}
+
+func TestRun_DeployLatestTagByVersion(t *testing.T) {
+	type pattern struct {
+		exp        string
+		mergedInto string
+		config     LatestTagConfig
+	}
+	patterns := []pattern{
+		{"Release v1.10.1", "", LatestTagConfig{By: "version"}},
+		{"Release v1.10.1", "HEAD", LatestTagConfig{By: "version", Reachable: true}},
+		{"Release v1.2.4", "", LatestTagConfig{}},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpDeployLatestTag{}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config:    Config{LatestTag: p.config, Components: map[string]ComponentConfig{"api": {}}},
+		}
+
+		code := cli.Run(strings.Split("gdp deploy -d", " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+		if fake.mergedInto != p.mergedInto {
+			t.Errorf("MergedInto=%q, Expected=%q", fake.mergedInto, p.mergedInto)
+		}
+	}
+}
+
+type FakeGdpDeployListTagsError struct {
+	FakeGdpDeploy
+}
+
+func (f *FakeGdpDeployListTagsError) ListTags(mergedInto string) ([]Tag, error) {
+	return nil, errors.New("fatal: not a git repository")
+}
+
+func TestRun_DeployListTagsError(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployListTagsError{},
+		config:    Config{LatestTag: LatestTagConfig{By: "version"}},
+	}
+
+	// the tag is not compared with the empty latest tag.
+	code := cli.Run(strings.Split("gdp deploy -t v1.2.4 -d", " "))
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Getting latest tag error: fatal: not a git repository."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+func TestRun_DeployInvalidLatestTag(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeploy{},
+		config:    Config{LatestTag: LatestTagConfig{By: "date"}},
+	}
+
+	code := cli.Run(strings.Split("gdp deploy -d", " "))
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Invalid config: latest_tag.by must be describe or version."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+func TestRun_DeployInvalidStrategy(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeploy{},
+	}
+
+	args := strings.Split("gdp deploy -t v1.2.4 --strategy squash", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "unknown commit strategy"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+type FakeGdpDeployContributors struct {
+	FakeGdpDeploy
+}
+
+func (f *FakeGdpDeployContributors) GetPreviousContributors(fromRef string) ([]Contributor, error) {
+	return []Contributor{{Name: "itosho"}}, nil
+}
+
+func TestRun_DeployContributors(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployContributors{},
+		config:    Config{Contributors: true},
+	}
+
+	args := strings.Split("gdp deploy -t v1.2.4 -d", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "## Contributors\n- itosho\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+type FakeGdpDeployHeader struct {
+	FakeGdpDeploy
+}
+
+func (f *FakeGdpDeployHeader) GetRangeStat(fromRef string, toRef string) (RangeStat, error) {
+	return RangeStat{FromTag: "v1.2.3", Commits: 2, FilesChanged: 1, Insertions: 3, Deletions: 1}, nil
+}
+
+func (f *FakeGdpDeployHeader) GetRemoteURL() (string, error) {
+	return "git@github.com:Connehito/gdp.git", nil
+}
+
+func TestRun_DeployHeader(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployHeader{},
+		config:    Config{Header: HeaderConfig{Compare: true, Stats: true}},
+	}
+
+	args := strings.Split("gdp deploy -t v1.2.4 -d", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "## v1.2.4\n"
+	expected = expected + "**Full Changelog**: https://github.com/Connehito/gdp/compare/v1.2.3...v1.2.4\n\n"
+	expected = expected + "2 commits, 1 file changed, 3 insertions(+), 1 deletion(-)\n\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+type FakeGdpDeployIssues struct {
+	FakeGdpDeploy
+}
+
+func (f *FakeGdpDeployIssues) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return []Commit{{Author: "itosho", Title: "PROJ-123 add feature"}}, nil
+}
+
+func TestRun_DeployIssues(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployIssues{},
+		config:    Config{Issues: IssuesConfig{Patterns: fakeIssuePatterns}},
+	}
+
+	path := filepath.Join(t.TempDir(), "issues.json")
+	args := []string{"gdp", "deploy", "-t", "v1.2.4", "-d", "--export-issues", path}
 	code := cli.Run(args)
 	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "- itosho: [PROJ-123](https://connehito.atlassian.net/browse/PROJ-123) add feature\n\n"
+	expected = expected + "## Issues resolved\n"
+	expected = expected + "- [PROJ-123](https://connehito.atlassian.net/browse/PROJ-123)\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Issues are not exported: %s", err.Error())
+	}
+}
+
+func TestRun_DeployInvalidIssuePattern(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeploy{},
+		config:    Config{Issues: IssuesConfig{Patterns: []IssuePattern{{Name: "jira", Pattern: "("}}}},
+	}
+
+	args := strings.Split("gdp deploy -t v1.2.4", " ")
+	code := cli.Run(args)
+	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "gdp publish done."
+	expected := "Invalid config: invalid issue pattern of jira"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+type FakeGdpDeployForce struct {
+	Gdp
+}
+
+func (f *FakeGdpDeployForce) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpDeployForce) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpDeployForce) Deploy(tag string) error {
+	return nil
+}
+
+func TestRun_DeployForce(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployForce{},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	args := strings.Split("gdp deploy -t v1.2.4 -f", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "gdp deploy done."
 	if !strings.Contains(out.String(), expected) {
 		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
 	}
 }
 
-type FakeGdpPublishNotExistTagInRemote struct {
+type FakeGdpDeployNotMasterBranch struct {
 	Gdp
 }
 
-func (f *FakeGdpPublishNotExistTagInRemote) IsExistTagInRemote(tag string) bool {
+func (f *FakeGdpDeployNotMasterBranch) IsMasterOrMainBranch() bool {
 	return false
 }
 
-func TestRun_PublishNotExistTagInRemote(t *testing.T) {
+func TestRun_DeployNotMasterBranch(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpPublishNotExistTagInRemote{},
+		gdp:       &FakeGdpDeployNotMasterBranch{},
 	}
 
-	args := strings.Split("gdp publish -t v1.2.3", " ")
+	args := strings.Split("gdp deploy -t v1.2.4", " ")
 	code := cli.Run(args)
 	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
 	}
 
-	expected := "Tag is not exist in remote."
+	expected := "Branch is not master or main."
 	if !strings.Contains(err.String(), expected) {
 		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
 	}
 }
 
-type FakeGdpPublishErrorInPublish struct {
+type FakeGdpDeployExistTagInLocal struct {
 	Gdp
 }
 
-func (f *FakeGdpPublishErrorInPublish) IsExistTagInRemote(tag string) bool {
+func (f *FakeGdpDeployExistTagInLocal) IsMasterOrMainBranch() bool {
 	return true
 }
 
-func (f *FakeGdpPublishErrorInPublish) GetMergeCommitList(toTag string) (string, error) {
-	list := "- itosho: initial commit\n"
-	list = list + "- itosho: fix bug"
-	return list, nil
+func (f *FakeGdpDeployExistTagInLocal) IsExistTagInLocal(tag string) bool {
+	return true
 }
 
-func (f *FakeGdpPublishErrorInPublish) Publish(tag string, commits string) error {
-	return errors.New("error occurred")
+func TestRun_DeployExistTagInLocal(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployExistTagInLocal{},
+	}
+
+	args := strings.Split("gdp deploy -t v1.2.4", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Tag is already exist in local."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
 }
 
-func TestRun_PublishErrorInPublish(t *testing.T) {
+type FakeGdpDeployErrorInGetNextTag struct {
+	Gdp
+}
+
+func (f *FakeGdpDeployErrorInGetNextTag) IsMasterOrMainBranch() bool {
+	return true
+}
+
+func (f *FakeGdpDeployErrorInGetNextTag) IsExistTagInLocal(tag string) bool {
+	return false
+}
+
+func (f *FakeGdpDeployErrorInGetNextTag) GetLatestTag(prefix string, excludes []string) string {
+	return "v1.2.semantic"
+}
+
+func TestRun_DeployErrorInGetNextTag(t *testing.T) {
 	out, err := new(bytes.Buffer), new(bytes.Buffer)
 	cli := &CLI{
 		outStream: out,
 		errStream: err,
-		gdp:       &FakeGdpPublishErrorInPublish{},
+		gdp:       &FakeGdpDeployErrorInGetNextTag{},
 	}
 
-	args := strings.Split("gdp publish -t v1.2.3", " ")
+	args := strings.Split("gdp deploy", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Getting release tag error"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+type FakeGdpDeployErrorInGetCommitList struct {
+	Gdp
+}
+
+func (f *FakeGdpDeployErrorInGetCommitList) IsMasterOrMainBranch() bool {
+	return true
+}
+
+func (f *FakeGdpDeployErrorInGetCommitList) IsExistTagInLocal(tag string) bool {
+	return false
+}
+
+func (f *FakeGdpDeployErrorInGetCommitList) IsValidTagName(tag string) bool {
+	return true
+}
+
+func (f *FakeGdpDeployErrorInGetCommitList) GetLatestTag(prefix string, excludes []string) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpDeployErrorInGetCommitList) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpDeployErrorInGetCommitList) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return nil, errors.New("error occurred")
+}
+
+func TestRun_ErrorInGetCommitList(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployErrorInGetCommitList{},
+	}
+
+	args := strings.Split("gdp deploy -t v1.2.4", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Getting merge commit error"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+type FakeGdpDeployErrorInDeploy struct {
+	Gdp
+}
+
+func (f *FakeGdpDeployErrorInDeploy) IsMasterOrMainBranch() bool {
+	return true
+}
+
+func (f *FakeGdpDeployErrorInDeploy) IsExistTagInLocal(tag string) bool {
+	return false
+}
+
+func (f *FakeGdpDeployErrorInDeploy) IsValidTagName(tag string) bool {
+	return true
+}
+
+func (f *FakeGdpDeployErrorInDeploy) GetLatestTag(prefix string, excludes []string) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpDeployErrorInDeploy) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpDeployErrorInDeploy) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpDeployErrorInDeploy) Deploy(tag string) error {
+	return errors.New("error occurred")
+}
+
+func TestRun_DeployErrorInDeploy(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeployErrorInDeploy{},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	args := strings.Split("gdp deploy -t v1.2.4", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Deploy execution error"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+// Tests for publish
+type FakeGdpPublish struct {
+	Gdp
+}
+
+func (f *FakeGdpPublish) IsExistTagInRemote(tag string) bool {
+	return true
+}
+
+func (f *FakeGdpPublish) GetLatestTag(prefix string, excludes []string) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpPublish) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpPublish) GetRelease(tag string) (*Release, error) {
+	return nil, nil
+}
+
+func (f *FakeGdpPublish) Publish(tag string, commits string, options PublishOptions) error {
+	return nil
+}
+
+func TestRun_Publish(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublish{},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "gdp publish done."
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+func TestRun_PublishDryRun(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublish{},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3 -dry-run", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "gdp publish done(dry-run mode)."
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+func TestRun_PublishNoSpecifiedTag(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublish{},
+	}
+
+	args := strings.Split("gdp publish", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "v1.2.3"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+func (f *FakeGdpPublish) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.2"
+}
+
+func (f *FakeGdpPublish) GetRemoteURL() (string, error) {
+	return "git@github.com:Connehito/gdp.git", nil
+}
+
+func TestRun_PublishNotification(t *testing.T) {
+	success, body, _ := fakeWebhook(t, http.StatusOK)
+	failure, _, _ := fakeWebhook(t, http.StatusInternalServerError)
+
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublish{},
+		config: Config{Notifications: []NotificationConfig{
+			{Type: NotifierWebhook, URL: failure.URL},
+			{Type: NotifierWebhook, URL: success.URL},
+			{Type: NotifierSlack, URL: success.URL, On: []string{CommandDeploy}},
+		}},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "Notification(webhook) error: unexpected status 500"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+	if (*body)["compare_url"] != "https://github.com/Connehito/gdp/compare/v1.2.2...v1.2.3" {
+		t.Errorf("Output=%v", *body)
+	}
+	if (*body)["text"] != nil {
+		t.Errorf("Slack is notified on publish: %v", *body)
+	}
+}
+
+type FakeGdpPublishOptions struct {
+	FakeGdpPublish
+	options   PublishOptions
+	finalized string
+}
+
+func (f *FakeGdpPublishOptions) Publish(tag string, commits string, options PublishOptions) error {
+	f.options = options
+	return nil
+}
+
+func (f *FakeGdpPublishOptions) FinalizeRelease(tag string, latest string) error {
+	f.finalized = tag + ":" + latest
+	return nil
+}
+
+func TestRun_PublishOptions(t *testing.T) {
+	type pattern struct {
+		exp  PublishOptions
+		args string
+	}
+	patterns := []pattern{
+		{PublishOptions{}, "gdp publish -t v1.2.3"},
+		{PublishOptions{Draft: true, Latest: "false"}, "gdp publish -t v1.2.3 --draft --latest false"},
+		{PublishOptions{Prerelease: true}, "gdp publish -t v1.2.3-rc.1"},
+		{PublishOptions{}, "gdp publish -t v1.2.3-rc.1 --prerelease=false"},
+		{PublishOptions{Prerelease: true}, "gdp publish -t v1.2.3 --prerelease"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpPublishOptions{}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Args=%q", code, ExitSuccess, p.args)
+		}
+		if fake.options != p.exp {
+			t.Errorf("Output=%v, Expected=%v, Args=%q", fake.options, p.exp, p.args)
+		}
+	}
+}
+
+func TestRun_PublishFinalize(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPublishOptions{}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3 --finalize --latest true", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "v1.2.3:true"
+	if fake.finalized != expected {
+		t.Errorf("Output=%q, Expected=%q", fake.finalized, expected)
+	}
+	if strings.Contains(out.String(), "The release note is as follows.") {
+		t.Errorf("Release note is generated on finalize: %q", out.String())
+	}
+}
+
+func TestRun_PublishInvalidOptions(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
+	}
+	patterns := []pattern{
+		{"are available for publish", "gdp deploy -t v1.2.4 --draft"},
+		{"--latest must be true, false or legacy", "gdp publish -t v1.2.3 --latest yes"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       &FakeGdpPublish{},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+	}
+}
+
+type FakeGdpPublishExistingRelease struct {
+	FakeGdpPublish
+	release *Release
+	updated string
+}
+
+func (f *FakeGdpPublishExistingRelease) GetRelease(tag string) (*Release, error) {
+	return f.release, nil
+}
+
+func (f *FakeGdpPublishExistingRelease) Publish(tag string, commits string, options PublishOptions) error {
+	return errors.New("release already exists")
+}
+
+func (f *FakeGdpPublishExistingRelease) UpdateRelease(tag string, message string) error {
+	f.updated = message
+	return nil
+}
+
+func TestRun_PublishExistingRelease(t *testing.T) {
+	type pattern struct {
+		exp     string
+		updated bool
+		body    string
+		args    string
+	}
+	patterns := []pattern{
+		{"The release of v1.2.3 is already up to date.", false, "## v1.2.3\r\n- itosho: initial commit\r\n- itosho: fix bug\r\n", "gdp publish -t v1.2.3 --update"},
+		{"- - itosho: old commit\n+ - itosho: initial commit\n+ - itosho: fix bug", false, "## v1.2.3\n- itosho: old commit", "gdp publish -t v1.2.3"},
+		{"Skipped publishing. Run with --update to update the release.", false, "## v1.2.3\n- itosho: old commit", "gdp publish -t v1.2.3"},
+		{"gdp publish done.", true, "## v1.2.3\n- itosho: old commit", "gdp publish -t v1.2.3 --update"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpPublishExistingRelease{release: &Release{TagName: "v1.2.3", Name: "Release v1.2.3", Body: p.body}}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+		}
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+		if (fake.updated != "") != p.updated {
+			t.Errorf("Updated=%q, Expected=%t, Args=%q", fake.updated, p.updated, p.args)
+		}
+	}
+}
+
+type FakeGdpPublishAssets struct {
+	FakeGdpPublish
+	uploaded []string
+}
+
+func (f *FakeGdpPublishAssets) UploadAssets(tag string, files []string) error {
+	for _, file := range files {
+		f.uploaded = append(f.uploaded, filepath.Base(file))
+	}
+	return nil
+}
+
+func TestRun_PublishAssets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"gdp_linux.tar.gz": "linux", "gdp_darwin.tar.gz": "darwin"})
+
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPublishAssets{}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{Assets: []string{filepath.Join(dir, "not_used")}},
+	}
+
+	args := []string{"gdp", "publish", "-t", "v1.2.3", "--asset", filepath.Join(dir, "*.tar.gz")}
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := []string{"gdp_darwin.tar.gz", "gdp_linux.tar.gz", ChecksumsFile}
+	if !reflect.DeepEqual(fake.uploaded, expected) {
+		t.Errorf("Output=%v, Expected=%v", fake.uploaded, expected)
+	}
+}
+
+func TestRun_PublishAssetsNotFound(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublishAssets{},
+		config:    Config{Assets: []string{filepath.Join(t.TempDir(), "*.tar.gz")}},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Preparing assets error"
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+type FakeGdpPublishExistingReleaseAssets struct {
+	FakeGdpPublishExistingRelease
+	uploaded []string
+}
+
+func (f *FakeGdpPublishExistingReleaseAssets) UploadAssets(tag string, files []string) error {
+	for _, file := range files {
+		f.uploaded = append(f.uploaded, filepath.Base(file))
+	}
+	return nil
+}
+
+func TestRun_PublishExistingReleaseAssets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"gdp_linux.tar.gz": "linux"})
+
+	type pattern struct {
+		exp  []string
+		body string
+		args []string
+	}
+	patterns := []pattern{
+		{[]string{"gdp_linux.tar.gz", ChecksumsFile}, "## v1.2.3\n- itosho: initial commit\n- itosho: fix bug", []string{"--update"}},
+		{[]string{"gdp_linux.tar.gz", ChecksumsFile}, "## v1.2.3\n- itosho: old commit", []string{}},
+		{nil, "## v1.2.3\n- itosho: initial commit\n- itosho: fix bug", []string{"--update", "-d"}},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpPublishExistingReleaseAssets{}
+		fake.release = &Release{TagName: "v1.2.3", Name: "Release v1.2.3", Body: p.body}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+
+		args := append([]string{"gdp", "publish", "-t", "v1.2.3", "--asset", filepath.Join(dir, "*.tar.gz")}, p.args...)
+		code := cli.Run(args)
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+		if !reflect.DeepEqual(fake.uploaded, p.exp) {
+			t.Errorf("Output=%v, Expected=%v, Args=%q", fake.uploaded, p.exp, p.args)
+		}
+		if fake.updated != "" {
+			t.Errorf("Release is updated: %q", fake.updated)
+		}
+	}
+}
+
+type FakeGdpPublishForce struct {
+	Gdp
+}
+
+func (f *FakeGdpPublishForce) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpPublishForce) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpPublishForce) GetRelease(tag string) (*Release, error) {
+	return nil, nil
+}
+
+func (f *FakeGdpPublishForce) Publish(tag string, commits string, options PublishOptions) error {
+	return nil
+}
+
+func TestRun_PublishForce(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublishForce{},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3 -f", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "gdp publish done."
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+type FakeGdpPublishNotExistTagInRemote struct {
+	Gdp
+}
+
+func (f *FakeGdpPublishNotExistTagInRemote) IsExistTagInRemote(tag string) bool {
+	return false
+}
+
+func TestRun_PublishNotExistTagInRemote(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublishNotExistTagInRemote{},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Tag is not exist in remote."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+type FakeGdpPublishErrorInPublish struct {
+	Gdp
+}
+
+func (f *FakeGdpPublishErrorInPublish) IsExistTagInRemote(tag string) bool {
+	return true
+}
+
+func (f *FakeGdpPublishErrorInPublish) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.3"
+}
+
+func (f *FakeGdpPublishErrorInPublish) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpPublishErrorInPublish) GetRelease(tag string) (*Release, error) {
+	return nil, nil
+}
+
+func (f *FakeGdpPublishErrorInPublish) Publish(tag string, commits string, options PublishOptions) error {
+	return errors.New("error occurred")
+}
+
+func TestRun_PublishErrorInPublish(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpPublishErrorInPublish{},
+	}
+
+	args := strings.Split("gdp publish -t v1.2.3", " ")
 	code := cli.Run(args)
 	if code != ExitError {
 		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
@@ -633,6 +1777,1235 @@ func TestRun_PublishErrorInPublish(t *testing.T) {
 	}
 }
 
+// Tests for release
+type FakeGdpRelease struct {
+	FakeGdpDeploy
+	deployed      bool
+	visible       bool
+	listed        int
+	published     string
+	publishErr    error
+	publishCalled bool
+}
+
+func (f *FakeGdpRelease) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	f.listed++
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpRelease) IsExistTagInRemote(tag string) bool {
+	return f.deployed && f.visible
+}
+
+func (f *FakeGdpRelease) Deploy(tag string) error {
+	f.deployed = true
+	return nil
+}
+
+func (f *FakeGdpRelease) Publish(tag string, commits string, options PublishOptions) error {
+	f.publishCalled = true
+	f.published = commits
+	return f.publishErr
+}
+
+func fakeTagWait(t *testing.T, attempts int) {
+	t.Helper()
+	t.Cleanup(func() {
+		tagWaitAttempts = 10
+		tagWaitInterval = 3 * time.Second
+	})
+	tagWaitAttempts = attempts
+	tagWaitInterval = 0
+}
+
+type FakeGdpPublishAnnotate struct {
+	*FakeGdpAnnotate
+}
+
+func (f *FakeGdpPublishAnnotate) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return []Commit{
+		{Author: "itosho", Title: "fix bug", Body: "Fixes #5", PR: 10},
+		{Author: "kazu", Title: "add feature", PR: 11},
+		{Author: "itosho", Title: "fix typo", PR: 12},
+	}, nil
+}
+
+func TestRun_PublishAnnotate(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPublishAnnotate{newFakeGdpAnnotate()}
+	fake.Gdp = &FakeGdpPublish{}
+	fake.comments[11] = []string{"Shipped v1.2.3"}
+	fake.failed = 12
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{Annotate: AnnotateConfig{Comment: "Shipped {tag}", Label: "released", CloseIssues: true}},
+	}
+
+	code := cli.Run(strings.Split("gdp publish -t v1.2.3 --annotate", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	for _, expected := range []string{"Annotated pull requests: 1 done, 1 skipped(already annotated), 1 failed.", "Closed issues: 1 done, 0 failed."} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		}
+	}
+	expected := "Annotation(#12) error: 403 Forbidden."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+	if !reflect.DeepEqual(fake.comments[10], []string{"Shipped v1.2.3"}) || !reflect.DeepEqual(fake.labels[10], []string{"released"}) {
+		t.Errorf("Comments=%q, Labels=%q", fake.comments[10], fake.labels[10])
+	}
+	if !reflect.DeepEqual(fake.closed, []int{5}) {
+		t.Errorf("Closed=%v, Expected=%v", fake.closed, []int{5})
+	}
+}
+
+func TestRun_DeployAnnotate(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeploy{},
+	}
+
+	code := cli.Run(strings.Split("gdp deploy -t v1.2.4 --annotate", " "))
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Invalid option: --annotate is available for publish, release and finalize."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+func TestRun_DeployMilestoneConfig(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDeploy{},
+		config:    Config{Milestone: MilestoneConfig{Enabled: true}},
+	}
+
+	// milestone.enabled is not applied to deploy, so the milestone is not requested.
+	code := cli.Run(strings.Split("gdp deploy -t v1.2.4 -d", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := "- itosho: initial commit"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+type FakeGdpPublishMilestone struct {
+	FakeGdpPublish
+	closed  int
+	created string
+}
+
+func (f *FakeGdpPublishMilestone) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return []Commit{{Author: "itosho", Title: "fix bug", PR: 10}, {Author: "kazu", Title: "fix typo", PR: 11}}, nil
+}
+
+func (f *FakeGdpPublishMilestone) GetMilestone(title string) (*Milestone, error) {
+	if title != "v1.2.3" {
+		return nil, nil
+	}
+	return &Milestone{Number: 3, Title: title, State: "open"}, nil
+}
+
+func (f *FakeGdpPublishMilestone) ListMilestonePullRequests(number int) ([]PullRequest, error) {
+	mergedAt := time.Date(2020, 4, 1, 10, 00, 00, 0, time.UTC)
+	return []PullRequest{
+		{Number: 10, Title: "fix bug", User: GitHubUser{Login: "itosho"}, MergedAt: &mergedAt},
+		{Number: 12, Title: "add feature", User: GitHubUser{Login: "kazu"}, MergedAt: &mergedAt},
+		{Number: 13, Title: "wip", User: GitHubUser{Login: "kazu"}},
+	}, nil
+}
+
+func (f *FakeGdpPublishMilestone) CloseMilestone(number int) error {
+	f.closed = number
+	return nil
+}
+
+func (f *FakeGdpPublishMilestone) CreateMilestone(title string) error {
+	f.created = title
+	return nil
+}
+
+func TestRun_PublishMilestone(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPublishMilestone{}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{Milestone: MilestoneConfig{Close: true, CreateNext: true}},
+	}
+
+	code := cli.Run(strings.Split("gdp publish -t v1.2.3 --milestone", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	for _, expected := range []string{"- itosho: fix bug (#10)\n- kazu: add feature (#12)\n", "Closed the milestone v1.2.3.", "Created the milestone v1.2.4."} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		}
+	}
+	warnings := "Warning: #11 is merged since previous tag but not in the milestone v1.2.3.\n"
+	warnings = warnings + "Warning: #12 is in the milestone v1.2.3 but not merged since previous tag.\n"
+	warnings = warnings + "Warning: #13 is in the milestone v1.2.3 but not merged since previous tag.\n"
+	if err.String() != warnings {
+		t.Errorf("Output=%q, Expected=%q", err.String(), warnings)
+	}
+	if fake.closed != 3 || fake.created != "v1.2.4" {
+		t.Errorf("Closed=%d, Created=%q, Expected=%d, %q", fake.closed, fake.created, 3, "v1.2.4")
+	}
+}
+
+func TestRun_Release(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpRelease{visible: true}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+	fakeTagWait(t, 2)
+
+	args := strings.Split("gdp release", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := "Summary:\n  [done]        deploy\n  [done]        wait\n  [done]        publish\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	if !strings.HasPrefix(fake.published, "Release v1.2.4\n") {
+		t.Errorf("Output=%q, Expected=%q", fake.published, "Release v1.2.4")
+	}
+	if fake.listed != 1 {
+		t.Errorf("Release note is generated %d times, Expected=1", fake.listed)
+	}
+}
+
+func TestRun_ReleaseDryRun(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpRelease{visible: true}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+	}
+
+	args := strings.Split("gdp release -t v1.2.4 -d", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "gdp release done(dry-run mode)."
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	if fake.deployed || fake.publishCalled {
+		t.Errorf("Executed in dry-run mode: deployed=%t, published=%t", fake.deployed, fake.publishCalled)
+	}
+}
+
+func TestRun_ReleaseFailed(t *testing.T) {
+	type pattern struct {
+		exp     string
+		errExp  string
+		visible bool
+		err     error
+	}
+	patterns := []pattern{
+		{"  [done]        deploy\n  [failed]      wait\n  [skipped]     publish\n", "wait step error: tag v1.2.4 is not found in remote after 2 attempts.", false, nil},
+		{"  [done]        deploy\n  [done]        wait\n  [failed]      publish\n", "publish step error: error occurred.", true, errors.New("error occurred")},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpRelease{visible: p.visible, publishErr: p.err}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+		fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+		fakeTagWait(t, 2)
+
+		args := strings.Split("gdp release -t v1.2.4", " ")
+		code := cli.Run(args)
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+		if !strings.Contains(err.String(), p.errExp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.errExp)
+		}
+	}
+}
+
+type FakeGdpReleaseRollback struct {
+	FakeGdpRelease
+	deleted string
+}
+
+func (f *FakeGdpReleaseRollback) DeleteTag(tag string, remote bool) error {
+	f.deleted = fmt.Sprintf("%s:%t", tag, remote)
+	return nil
+}
+
+func TestRun_ReleaseRollback(t *testing.T) {
+	type pattern struct {
+		exp     string
+		deleted string
+		args    string
+	}
+	patterns := []pattern{
+		{"  [rolled back] deploy\n  [done]        wait\n  [failed]      publish\n", "v1.2.4:true", "gdp release -t v1.2.4 --rollback"},
+		{"  [done]        deploy\n  [done]        wait\n  [failed]      publish\n", "", "gdp release -t v1.2.4"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpReleaseRollback{FakeGdpRelease: FakeGdpRelease{visible: true, publishErr: errors.New("error occurred")}}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+		fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+		if fake.deleted != p.deleted {
+			t.Errorf("Deleted=%q, Expected=%q", fake.deleted, p.deleted)
+		}
+	}
+}
+
+type FakeGdpReleaseVersionFiles struct {
+	FakeGdpReleaseRollback
+	deployErr error
+	undone    bool
+	pushed    bool
+}
+
+func (f *FakeGdpReleaseVersionFiles) Deploy(tag string) error {
+	if f.deployErr != nil {
+		return f.deployErr
+	}
+	return f.FakeGdpReleaseRollback.Deploy(tag)
+}
+
+func (f *FakeGdpReleaseVersionFiles) CommitFiles(files []string, message string) error {
+	return nil
+}
+
+func (f *FakeGdpReleaseVersionFiles) UndoCommit() error {
+	f.undone = true
+	return nil
+}
+
+func (f *FakeGdpReleaseVersionFiles) PushBranch() error {
+	f.pushed = true
+	return nil
+}
+
+func TestRun_ReleaseVersionFiles(t *testing.T) {
+	type pattern struct {
+		exp        string
+		args       string
+		deployErr  error
+		publishErr error
+		undone     bool
+		pushed     bool
+	}
+	patterns := []pattern{
+		{"  [done]        bump\n  [done]        deploy\n  [done]        wait\n  [done]        publish\n  [done]        push\n", "gdp release -t v1.2.4 --rollback", nil, nil, false, true},
+		{"  [rolled back] bump\n  [rolled back] deploy\n  [done]        wait\n  [failed]      publish\n  [skipped]     push\n", "gdp release -t v1.2.4 --rollback", nil, errors.New("error occurred"), true, false},
+		// without --rollback, the version commit is removed like deploy when the tag is not pushed,
+		{"  [rolled back] bump\n  [failed]      deploy\n  [skipped]     push\n", "gdp release -t v1.2.4", errors.New("error occurred"), nil, true, false},
+		// and it is pushed with the kept tag.
+		{"  [done]        bump\n  [done]        deploy\n  [done]        push\n  [done]        wait\n  [failed]      publish\n", "gdp release -t v1.2.4", nil, errors.New("error occurred"), false, true},
+	}
+
+	for _, p := range patterns {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n"})
+
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpReleaseVersionFiles{FakeGdpReleaseRollback: FakeGdpReleaseRollback{FakeGdpRelease: FakeGdpRelease{visible: true, publishErr: p.publishErr}}, deployErr: p.deployErr}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config:    Config{VersionFiles: VersionFilesConfig{Files: []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}}}},
+		}
+		fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+		cli.Run(strings.Split(p.args, " "))
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+		if fake.undone != p.undone || fake.pushed != p.pushed {
+			t.Errorf("Undone=%t, Pushed=%t, Expected=%t, %t", fake.undone, fake.pushed, p.undone, p.pushed)
+		}
+	}
+}
+
+func TestRun_ReleaseRollbackAfterPublish(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"gdp.tar.gz": "gdp"})
+
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpReleaseRollbackUpload{FakeGdpReleaseRollback: FakeGdpReleaseRollback{FakeGdpRelease: FakeGdpRelease{visible: true}}}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{Rollback: true},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	args := []string{"gdp", "release", "-t", "v1.2.4", "--asset", filepath.Join(dir, "*.tar.gz")}
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	// published release can't be rolled back, so the tag is kept.
+	if fake.deleted != "" {
+		t.Errorf("Deleted=%q, Expected=%q", fake.deleted, "")
+	}
+}
+
+type FakeGdpReleaseRollbackUpload struct {
+	FakeGdpReleaseRollback
+}
+
+func (f *FakeGdpReleaseRollbackUpload) UploadAssets(tag string, files []string) error {
+	return errors.New("error occurred")
+}
+
+type FakeGdpReleaseExistTagInRemote struct {
+	FakeGdpDeploy
+}
+
+func (f *FakeGdpReleaseExistTagInRemote) IsExistTagInRemote(tag string) bool {
+	return true
+}
+
+func TestRun_ReleaseExistTagInRemote(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpReleaseExistTagInRemote{},
+	}
+
+	args := strings.Split("gdp release -t v1.2.4", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Tag is already exist in remote."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+// Tests for list
+type FakeGdpList struct {
+	Gdp
+}
+
+func (f *FakeGdpList) ListTags(mergedInto string) ([]Tag, error) {
+	return []Tag{
+		{Name: "v1.2.4", Date: time.Date(2020, 4, 3, 10, 0, 0, 0, time.Local), Tagger: "itosho", Commit: "1234567890abcdef"},
+		{Name: "latest", Date: time.Date(2020, 4, 2, 12, 0, 0, 0, time.Local), Tagger: "itosho", Commit: "abcdef"},
+		{Name: "v1.2.3", Date: time.Date(2020, 4, 2, 10, 0, 0, 0, time.Local), Tagger: "kazu", Commit: "fedcba0987654321"},
+		{Name: "v1.2.2", Date: time.Date(2020, 4, 1, 10, 0, 0, 0, time.Local), Tagger: "kazu", Commit: "0000000000000000"},
+	}, nil
+}
+
+func (f *FakeGdpList) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	return map[string][]Commit{
+		"v1.2.3..v1.2.4": {{PR: 13}, {PR: 12}, {PR: 12}, {PR: 0}, {PR: 11}},
+		"v1.2.2..v1.2.3": {{PR: 10}, {PR: 9}},
+		"..v1.2.2":       {{PR: 8}, {PR: 0}},
+	}[fromRef+".."+toRef], nil
+}
+
+func (f *FakeGdpList) ListReleases() ([]Release, error) {
+	return []Release{{TagName: "v1.2.3"}, {TagName: "v1.2.4", Draft: true}}, nil
+}
+
+func (f *FakeGdpList) ListDeployments() ([]Deployment, error) {
+	return nil, nil
+}
+
+type FakeGdpListDeployments struct {
+	FakeGdpList
+}
+
+func (f *FakeGdpListDeployments) ListDeployments() ([]Deployment, error) {
+	return []Deployment{
+		{ID: 3, SHA: "1234567890abcdef", Environment: "production"},
+		{ID: 2, SHA: "1234567890abcdef", Environment: "staging"},
+		{ID: 1, SHA: "fedcba0987654321", Environment: "production"},
+	}, nil
+}
+
+func (f *FakeGdpListDeployments) GetDeploymentState(id int) (string, error) {
+	return map[int]string{3: DeploymentFailure, 2: DeploymentSuccess, 1: DeploymentSuccess}[id], nil
+}
+
+func TestRun_ListDeployments(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpListDeployments{},
+	}
+
+	code := cli.Run(strings.Split("gdp list", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := "TAG     DATE              TAGGER  COMMIT   PRS  PUBLISHED  DEPLOYED\n"
+	expected = expected + "v1.2.4  2020-04-03 10:00  itosho  1234567  3    no         staging\n"
+	expected = expected + "v1.2.3  2020-04-02 10:00  kazu    fedcba0  2    yes        production\n"
+	expected = expected + "v1.2.2  2020-04-01 10:00  kazu    0000000  1    no         -\n"
+	if out.String() != expected {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+type FakeGdpListDeploymentsError struct {
+	FakeGdpList
+}
+
+func (f *FakeGdpListDeploymentsError) ListDeployments() ([]Deployment, error) {
+	return nil, errors.New("403 Forbidden")
+}
+
+func TestRun_ListDeploymentsError(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpListDeploymentsError{},
+	}
+
+	code := cli.Run(strings.Split("gdp list", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := "TAG     DATE              TAGGER  COMMIT   PRS  PUBLISHED\n"
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	warning := "Warning: the deployed environments are not shown: 403 Forbidden."
+	if !strings.Contains(err.String(), warning) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), warning)
+	}
+}
+
+type FakeGdpPrepare struct {
+	FakeGdpDeploy
+	existingPR     *PullRequest
+	existingBranch bool
+	branch         string
+	files          []string
+	changes        []FileChange
+	message        string
+	title          string
+	body           string
+}
+
+func (f *FakeGdpPrepare) GetPullRequest(head string) (*PullRequest, error) {
+	return f.existingPR, nil
+}
+
+func (f *FakeGdpPrepare) IsExistBranch(branch string) bool {
+	return f.existingBranch
+}
+
+func (f *FakeGdpPrepare) PushReleaseBranch(branch string, changes []FileChange, message string) (string, error) {
+	f.branch, f.message = branch, message
+	for _, c := range changes {
+		f.files = append(f.files, c.Path)
+	}
+	f.changes = changes
+	return "main", nil
+}
+
+func (f *FakeGdpPrepare) CreatePullRequest(base string, head string, title string, body string) (*PullRequest, error) {
+	f.title, f.body = title, body
+	return &PullRequest{Number: 12, HTMLURL: "https://github.com/Connehito/gdp/pull/12"}, nil
+}
+
+func TestRun_Prepare(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n"})
+	changelog := filepath.Join(dir, "CHANGELOG.md")
+
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPrepare{}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config: Config{
+			ReleasePR:    ReleasePRConfig{Changelog: changelog},
+			VersionFiles: VersionFilesConfig{Files: []VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}}},
+		},
+	}
+
+	code := cli.Run(strings.Split("gdp prepare", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := "Created the release pull request #12: https://github.com/Connehito/gdp/pull/12"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	if fake.branch != "release/v1.2.4" || fake.message != "Bump version to v1.2.4" {
+		t.Errorf("Branch=%q, Message=%q, Expected=%q, %q", fake.branch, fake.message, "release/v1.2.4", "Bump version to v1.2.4")
+	}
+	if !reflect.DeepEqual(fake.files, []string{changelog, filepath.Join(dir, "VERSION")}) {
+		t.Errorf("Files=%q, Expected=%q", fake.files, []string{changelog, filepath.Join(dir, "VERSION")})
+	}
+	if fake.title != "Release v1.2.4" || !strings.HasPrefix(fake.body, "## v1.2.4\n") {
+		t.Errorf("Title=%q, Body=%q, Expected=%q, %q", fake.title, fake.body, "Release v1.2.4", "## v1.2.4")
+	}
+	if fake.changes[0].After != strings.TrimRight(fake.body, "\n")+"\n" {
+		t.Errorf("Output=%q, Expected=%q", fake.changes[0].After, fake.body)
+	}
+	// the files are written on the release branch, not the current branch.
+	if _, err := os.Stat(changelog); !os.IsNotExist(err) {
+		t.Errorf("Changelog is written on the current branch: %v", err)
+	}
+}
+
+func TestRun_PrepareDryRun(t *testing.T) {
+	changelog := filepath.Join(t.TempDir(), "CHANGELOG.md")
+
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPrepare{}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{ReleasePR: ReleasePRConfig{Changelog: changelog}},
+	}
+
+	code := cli.Run(strings.Split("gdp prepare -d", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	for _, expected := range []string{"--- " + changelog + "\n+++ " + changelog + "\n+ ## v1.2.4\n", "gdp prepare done(dry-run mode)."} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		}
+	}
+	if _, statErr := os.Stat(changelog); statErr == nil || fake.branch != "" {
+		t.Errorf("Changelog is written or the branch %q is pushed in dry-run mode", fake.branch)
+	}
+}
+
+func TestRun_PrepareExistingBranch(t *testing.T) {
+	type pattern struct {
+		exp  string
+		fake *FakeGdpPrepare
+	}
+	patterns := []pattern{
+		{"Release pull request #12(open) of release/v1.2.4 already exists: https://github.com/Connehito/gdp/pull/12", &FakeGdpPrepare{existingPR: &PullRequest{Number: 12, State: "open", HTMLURL: "https://github.com/Connehito/gdp/pull/12"}}},
+		{"Release branch release/v1.2.4 already exists.", &FakeGdpPrepare{existingBranch: true}},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       p.fake,
+			config:    Config{ReleasePR: ReleasePRConfig{Changelog: filepath.Join(t.TempDir(), "CHANGELOG.md")}},
+		}
+
+		code := cli.Run(strings.Split("gdp prepare -t v1.2.4", " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+		if p.fake.branch != "" {
+			t.Errorf("Branch %q is pushed", p.fake.branch)
+		}
+	}
+}
+
+type FakeGdpFinalize struct {
+	FakeGdpRelease
+	pr     *PullRequest
+	commit string
+}
+
+func (f *FakeGdpFinalize) GetPullRequest(head string) (*PullRequest, error) {
+	if head != "release/v1.2.4" {
+		return nil, nil
+	}
+	return f.pr, nil
+}
+
+func (f *FakeGdpFinalize) DeployCommit(tag string, commit string) error {
+	f.deployed, f.commit = true, commit
+	return nil
+}
+
+func TestRun_Finalize(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	mergedAt := time.Date(2020, 4, 1, 10, 00, 00, 0, time.UTC)
+	fake := &FakeGdpFinalize{
+		FakeGdpRelease: FakeGdpRelease{visible: true},
+		pr:             &PullRequest{Number: 12, Title: "Release v1.2.4", Body: "## v1.2.4\n- itosho: reviewed", MergedAt: &mergedAt, MergeCommitSHA: "abc1234"},
+	}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+	fakeTagWait(t, 2)
+
+	code := cli.Run(strings.Split("gdp finalize", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	expected := "Summary:\n  [done]        deploy\n  [done]        wait\n  [done]        publish\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+	if fake.commit != "abc1234" {
+		t.Errorf("Output=%q, Expected=%q", fake.commit, "abc1234")
+	}
+	if fake.published != "Release v1.2.4\n\n## v1.2.4\n- itosho: reviewed" {
+		t.Errorf("Output=%q, Expected=%q", fake.published, "Release v1.2.4\n\n## v1.2.4\n- itosho: reviewed")
+	}
+}
+
+func TestRun_FinalizeNotMerged(t *testing.T) {
+	type pattern struct {
+		exp  string
+		pr   *PullRequest
+		args string
+	}
+	patterns := []pattern{
+		{"Release pull request #12 is not merged.", &PullRequest{Number: 12}, "gdp finalize"},
+		{"Release pull request of release/v1.2.5 is not found. Run gdp prepare first.", &PullRequest{Number: 12}, "gdp finalize -t v1.2.5"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpFinalize{pr: p.pr}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+		if fake.deployed {
+			t.Errorf("Tag is deployed before the pull request is merged")
+		}
+	}
+}
+
+type FakeGdpPromote struct {
+	FakeGdpDeploy
+	tags     []Tag
+	remote   []string
+	previous []string
+	toRef    string
+	fromRef  string
+	deployed string
+	commit   string
+}
+
+func (f *FakeGdpPromote) ListTags(mergedInto string) ([]Tag, error) {
+	return f.tags, nil
+}
+
+func (f *FakeGdpPromote) IsExistTagInRemote(tag string) bool {
+	for _, r := range f.remote {
+		if r == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *FakeGdpPromote) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	f.toRef = toRef
+	return previousTag(scheme, tag, f.previous)
+}
+
+func (f *FakeGdpPromote) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	f.fromRef = fromRef
+	return fakeCommits, nil
+}
+
+func (f *FakeGdpPromote) DeployCommit(tag string, commit string) error {
+	f.deployed, f.commit = tag, commit
+	return nil
+}
+
+func newFakeGdpPromote() *FakeGdpPromote {
+	return &FakeGdpPromote{
+		tags: []Tag{
+			{Name: "stg-20200401.2", Commit: "bbb"},
+			{Name: "stg-20200401.1", Commit: "aaa"},
+			{Name: "20200331.1", Commit: "000"},
+		},
+		remote:   []string{"stg-20200401.2", "stg-20200401.1", "20200331.1"},
+		previous: []string{"stg-20200401.1", "20200331.1"},
+	}
+}
+
+func TestRun_Promote(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := newFakeGdpPromote()
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{Scheme: "date", Environments: map[string]EnvironmentConfig{"staging": {Prefix: "stg-"}, "production": {}}},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	code := cli.Run(strings.Split("gdp promote --from staging --to production", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	for _, expected := range []string{"Promote stg-20200401.2(staging) to 20200401.2(production).", "## 20200401.2", "gdp promote done."} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		}
+	}
+	if fake.deployed != "20200401.2" || fake.commit != "bbb" {
+		t.Errorf("Tag=%q, Commit=%q, Expected=%q, %q", fake.deployed, fake.commit, "20200401.2", "bbb")
+	}
+	if fake.toRef != "bbb" || fake.fromRef != "20200331.1" {
+		t.Errorf("Range=%s..%s, Expected=%s..%s", fake.fromRef, fake.toRef, "20200331.1", "bbb")
+	}
+}
+
+func TestRun_PromoteSemVer(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	fake := &FakeGdpPromote{
+		tags: []Tag{
+			{Name: "stg-v1.2.4", Commit: "bbb"},
+			{Name: "stg-v1.2.3", Commit: "aaa"},
+			{Name: "v1.2.3", Commit: "aaa"},
+		},
+		remote:   []string{"stg-v1.2.4", "stg-v1.2.3", "v1.2.3"},
+		previous: []string{"stg-v1.2.3", "v1.2.3"},
+	}
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       fake,
+		config:    Config{Scheme: "semver", Environments: map[string]EnvironmentConfig{"staging": {Prefix: "stg-"}, "production": {}}},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	code := cli.Run(strings.Split("gdp promote --from staging --to production", " "))
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+	}
+
+	if fake.deployed != "v1.2.4" || fake.commit != "bbb" {
+		t.Errorf("Tag=%q, Commit=%q, Expected=%q, %q", fake.deployed, fake.commit, "v1.2.4", "bbb")
+	}
+	if fake.fromRef != "v1.2.3" {
+		t.Errorf("Output=%q, Expected=%q", fake.fromRef, "v1.2.3")
+	}
+}
+
+func TestRun_PromoteInvalid(t *testing.T) {
+	type pattern struct {
+		exp  string
+		tags []Tag
+		args string
+	}
+	patterns := []pattern{
+		{"Tag stg-20200401.2 is already promoted to production: 20200401.1.", []Tag{{Name: "stg-20200401.2", Commit: "bbb"}, {Name: "20200401.1", Commit: "bbb"}}, "gdp promote --from staging --to production"},
+		{"Tag stg-20200402.1 is not exist in remote.", []Tag{{Name: "stg-20200402.1", Commit: "ccc"}}, "gdp promote --from staging --to qa"},
+		{"Tag is not greater than the latest tag. The latest tag of production is 20200401.2.", []Tag{{Name: "stg-20200401.1", Commit: "aaa"}, {Name: "20200401.2", Commit: "bbb"}}, "gdp promote --from staging --to production"},
+		{"Tag of staging is not found.", []Tag{{Name: "20200401.1", Commit: "aaa"}}, "gdp promote --from staging --to production"},
+		{"Tag of staging is not found.", []Tag{{Name: "stg-20200401.1", Commit: "aaa"}}, "gdp promote --from staging --to production -t 20200401.1"},
+		{"unknown environment \"prod\"(configured: production, qa, staging)", nil, "gdp promote --from staging --to prod"},
+		{"Invalid option: promote requires --from and --to.", nil, "gdp promote --from staging"},
+		{"Invalid option: --from and --to are available for promote.", nil, "gdp deploy --from staging"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpPromote{tags: p.tags, remote: []string{"stg-20200401.2", "stg-20200401.1"}}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+			config: Config{Scheme: "date", Environments: map[string]EnvironmentConfig{
+				"staging": {Prefix: "stg-"}, "production": {}, "qa": {Prefix: "qa-"},
+			}},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+		if fake.deployed != "" {
+			t.Errorf("Tag %s is promoted", fake.deployed)
+		}
+	}
+}
+
+func TestRun_List(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpList{},
+	}
+
+	args := strings.Split("gdp list", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "TAG     DATE              TAGGER  COMMIT   PRS  PUBLISHED\n"
+	expected = expected + "v1.2.4  2020-04-03 10:00  itosho  1234567  3    no\n"
+	expected = expected + "v1.2.3  2020-04-02 10:00  kazu    fedcba0  2    yes\n"
+	expected = expected + "v1.2.2  2020-04-01 10:00  kazu    0000000  1    no\n"
+	if out.String() != expected {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+func TestRun_ListJSON(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpList{},
+	}
+
+	args := strings.Split("gdp list --json --limit 2 --since-date 2020-04-02", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	var histories []ReleaseHistory
+	if err := json.Unmarshal(out.Bytes(), &histories); err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 2 || histories[1].Name != "v1.2.3" || histories[1].PullRequests != 2 || !histories[1].Published {
+		t.Errorf("Output=%v", histories)
+	}
+
+	args = strings.Split("gdp list --json --since-date 2020-04-03", " ")
+	out.Reset()
+	cli.Run(args)
+	histories = nil
+	if err := json.Unmarshal(out.Bytes(), &histories); err != nil {
+		t.Fatal(err)
+	}
+	if len(histories) != 1 || histories[0].Name != "v1.2.4" {
+		t.Errorf("Output=%v", histories)
+	}
+}
+
+func TestRun_ListInvalidSince(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpList{},
+	}
+
+	args := strings.Split("gdp list --since-date 20200402", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "--since-date must be YYYY-MM-DD format."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
+func TestRun_ListInvalidOptions(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
+	}
+	patterns := []pattern{
+		{"Invalid option: --tag, --dry-run and --force are not available for list, status and diff.", "gdp list -t v1.2.3"},
+		{"Invalid option: --tag, --dry-run and --force are not available for list, status and diff.", "gdp status -d"},
+		{"Invalid option: --draft, --prerelease, --latest and --asset are available for publish, release and finalize.", "gdp list --draft"},
+		{"Invalid option: --draft, --prerelease, --latest and --asset are available for publish, release and finalize.", "gdp diff v1.4.0 v1.9.2 --asset app"},
+		{"Invalid option: --rollback is available for release and finalize.", "gdp list --rollback"},
+		{"Invalid option: --annotate is available for publish, release and finalize.", "gdp status --annotate"},
+		{"Invalid option: --environment is available for deploy.", "gdp list --environment production"},
+		{"Invalid option: --milestone is available for publish and release.", "gdp diff v1.4.0 v1.9.2 --milestone"},
+		{"Invalid option: --from and --to are available for promote.", "gdp status --from staging"},
+		{"Invalid option: --limit and --json are available for list.", "gdp status --json"},
+		{"Invalid option: --limit and --json are available for list.", "gdp deploy --limit 1"},
+		{"Invalid option: --group is available for diff.", "gdp list --group"},
+		{"Invalid option: --since is not available for list and diff.", "gdp list --since 2020-04-01"},
+		{"Invalid option: --since is not available for list and diff.", "gdp diff v1.4.0 v1.9.2 --since v1.2.0"},
+		{"Invalid option: --since-date is available for list.", "gdp status --since-date 2020-04-01"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       &FakeGdpList{},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitError {
+			t.Errorf("ExitCode=%d, Expected=%d, Args=%q", code, ExitError, p.args)
+		}
+		if !strings.Contains(err.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", err.String(), p.exp)
+		}
+	}
+}
+
+// Tests for status
+type FakeGdpStatus struct {
+	FakeGdpDeploy
+	branch bool
+}
+
+func (f *FakeGdpStatus) IsMasterOrMainBranch() bool {
+	return f.branch
+}
+
+func TestRun_Status(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpStatus{branch: true},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	args := strings.Split("gdp status", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	expected := "Latest tag:   v1.2.3\n"
+	expected = expected + "Next tag:     v1.2.4\n"
+	expected = expected + "Branch:       ok(master or main)\n"
+	expected = expected + "Safety hour:  ok(09:00-19:00)\n"
+	expected = expected + "Validations: ok\n"
+	expected = expected + "Pending commits(2):\n"
+	expected = expected + "- itosho: initial commit\n"
+	expected = expected + "- itosho: fix bug\n"
+	if out.String() != expected {
+		t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+	}
+}
+
+type FakeGdpStatusRange struct {
+	FakeGdpStatus
+	fromRef string
+}
+
+func (f *FakeGdpStatusRange) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	return "v1.2.2"
+}
+
+func (f *FakeGdpStatusRange) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	f.fromRef = fromRef
+	return nil, nil
+}
+
+func TestRun_StatusRange(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
+	}
+	patterns := []pattern{
+		{"v1.2.3", "gdp status"},
+		{"abc1234", "gdp status --since abc1234"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		fake := &FakeGdpStatusRange{FakeGdpStatus: FakeGdpStatus{branch: true}}
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       fake,
+		}
+		fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+		if fake.fromRef != p.exp {
+			t.Errorf("Output=%q, Expected=%q", fake.fromRef, p.exp)
+		}
+		if !strings.Contains(out.String(), "Pending commits(0):") {
+			t.Errorf("Output=%q, Expected=%q", out.String(), "Pending commits(0):")
+		}
+	}
+}
+
+func TestRun_StatusValidationFailed(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpStatus{branch: false},
+	}
+	fakeNow(t, time.Date(2020, 4, 1, 20, 00, 00, 0, time.Local))
+
+	args := strings.Split("gdp status", " ")
+	code := cli.Run(args)
+	if code != ExitSuccess {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitSuccess)
+	}
+
+	for _, expected := range []string{
+		"Branch:       ng(master or main)\n",
+		"Safety hour:  ng(09:00-19:00)\n",
+		"Validations: the following would fail\n  - Branch is not master or main.\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), expected)
+		}
+	}
+}
+
+// Tests for diff
+type FakeGdpDiff struct {
+	Gdp
+}
+
+func (f *FakeGdpDiff) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	commits := map[string][]Commit{
+		"v1.4.0..v1.9.2": {{Author: "kazu", Title: "add feature", PR: 3}, {Author: "itosho", Title: "fix bug", PR: 2}, {Author: "itosho", Title: "fix bug", PR: 2}},
+		"v1.4.0..v1.5.0": {{Author: "itosho", Title: "fix bug", PR: 2}},
+		"v1.5.0..v1.9.2": {{Author: "kazu", Title: "add feature", PR: 3}, {Author: "itosho", Title: "fix bug(cherry-pick)", PR: 2}},
+	}
+	return commits[fromRef+".."+toRef], nil
+}
+
+func (f *FakeGdpDiff) GetTagsBetween(fromRef string, toRef string) ([]string, error) {
+	return []string{"v1.5.0"}, nil
+}
+
+func TestRun_Diff(t *testing.T) {
+	type pattern struct {
+		exp  string
+		args string
+	}
+	patterns := []pattern{
+		{"Release v1.4.0...v1.9.2\n\n## v1.4.0...v1.9.2\n- kazu: add feature (#3)\n- itosho: fix bug (#2)\n", "gdp diff v1.4.0 v1.9.2"},
+		{"Release v1.4.0...v1.9.2\n\n## v1.9.2\n- kazu: add feature (#3)\n- itosho: fix bug(cherry-pick) (#2)\n\n## v1.5.0\n\n", "gdp diff v1.4.0 v1.9.2 --group"},
+	}
+
+	for _, p := range patterns {
+		out, err := new(bytes.Buffer), new(bytes.Buffer)
+		cli := &CLI{
+			outStream: out,
+			errStream: err,
+			gdp:       &FakeGdpDiff{},
+		}
+
+		code := cli.Run(strings.Split(p.args, " "))
+		if code != ExitSuccess {
+			t.Errorf("ExitCode=%d, Expected=%d, Error=%q", code, ExitSuccess, err.String())
+		}
+		if !strings.Contains(out.String(), p.exp) {
+			t.Errorf("Output=%q, Expected=%q", out.String(), p.exp)
+		}
+	}
+}
+
+func TestRun_DiffFewArg(t *testing.T) {
+	out, err := new(bytes.Buffer), new(bytes.Buffer)
+	cli := &CLI{
+		outStream: out,
+		errStream: err,
+		gdp:       &FakeGdpDiff{},
+	}
+
+	args := strings.Split("gdp diff v1.4.0 --group", " ")
+	code := cli.Run(args)
+	if code != ExitError {
+		t.Errorf("ExitCode=%d, Expected=%d", code, ExitError)
+	}
+
+	expected := "Too few argument."
+	if !strings.Contains(err.String(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.String(), expected)
+	}
+}
+
 func TestIsSafetyHour(t *testing.T) {
 	type pattern struct {
 		exp  bool
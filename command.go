@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"os/user"
+	"strconv"
 	"strings"
 )
 
@@ -13,10 +16,51 @@ type Gdp interface {
 	IsMasterOrMainBranch() bool
 	IsExistTagInLocal(tag string) bool
 	IsExistTagInRemote(tag string) bool
-	GetMergeCommitList(toTag string) (string, error)
-	GetLatestTag() string
+	IsExistBranch(branch string) bool
+	IsValidTagName(tag string) bool
+	GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error)
+	GetTagsBetween(fromRef string, toRef string) ([]string, error)
+	GetPreviousContributors(fromRef string) ([]Contributor, error)
+	GetRangeStat(fromRef string, toRef string) (RangeStat, error)
+	GetRemoteURL() (string, error)
+	GetLatestTag(prefix string, excludes []string) string
+	GetPreviousTag(tag string, toRef string, scheme TagScheme) string
+	ListTags(mergedInto string) ([]Tag, error)
+	ListReleases() ([]Release, error)
+	CommitFiles(files []string, message string) error
+	UndoCommit() error
+	PushBranch() error
 	Deploy(tag string) error
-	Publish(tag string, commits string) error
+	DeployCommit(tag string, commit string) error
+	PushReleaseBranch(branch string, changes []FileChange, message string) (string, error)
+	CreatePullRequest(base string, head string, title string, body string) (*PullRequest, error)
+	GetPullRequest(head string) (*PullRequest, error)
+	GetComments(number int) ([]string, error)
+	AddComment(number int, body string) error
+	AddLabels(number int, labels []string) error
+	CloseIssue(number int) error
+	GetMilestone(title string) (*Milestone, error)
+	ListMilestonePullRequests(number int) ([]PullRequest, error)
+	CloseMilestone(number int) error
+	CreateMilestone(title string) error
+	CreateDeployment(ref string, environment string, description string) (int, error)
+	SetDeploymentStatus(id int, state string) error
+	ListDeployments() ([]Deployment, error)
+	GetDeploymentState(id int) (string, error)
+	DeleteTag(tag string, remote bool) error
+	Publish(tag string, commits string, options PublishOptions) error
+	FinalizeRelease(tag string, latest string) error
+	GetRelease(tag string) (*Release, error)
+	UpdateRelease(tag string, message string) error
+	UploadAssets(tag string, files []string) error
+}
+
+// PublishOptions is the options of the release.
+type PublishOptions struct {
+	Draft      bool
+	Prerelease bool
+	// Latest is the "latest" marker(true, false or legacy). Empty means GitHub's default.
+	Latest string
 }
 
 // Command implements Git interface.
@@ -82,25 +126,115 @@ func (c *Command) IsExistTagInRemote(tag string) bool {
 	return true
 }
 
-// GetMergeCommitList gets merge-commits list from previous tag to the tag
-func (c *Command) GetMergeCommitList(toTag string) (string, error) {
-	fromTag := getPreviousTag(toTag)
-	if fromTag != "" {
-		fromTag = fromTag + ".."
+// IsValidTagName checks the tag is valid as the name of git's tag.
+func (c *Command) IsValidTagName(tag string) bool {
+	return exec.Command("git", "check-ref-format", "refs/tags/"+tag).Run() == nil
+}
+
+// GetCommitList gets commits list from the ref to the ref which are selected by the strategy.
+// Empty fromRef means the root commit. Empty paths means the whole repository.
+func (c *Command) GetCommitList(fromRef string, toRef string, strategy CommitStrategy, paths []string) ([]Commit, error) {
+	revRange := toRef
+	if fromRef != "" {
+		revRange = fromRef + ".." + toRef
+	}
+
+	args := append([]string{"log"}, strategy.logArgs()...)
+	args = append(args, commitLogFormat, revRange)
+	args = append(args, pathArgs(paths)...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+
+	return parseCommitLog(string(out)), nil
+}
+
+// GetTagsBetween gets tags between the refs in order of oldest first. fromRef and toRef are not included.
+func (c *Command) GetTagsBetween(fromRef string, toRef string) ([]string, error) {
+	out, err := exec.Command("git", "tag", "--merged", toRef, "--no-merged", fromRef, "--sort=creatordate").Output()
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+
+	tags := []string{}
+	for _, tag := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if tag == "" || tag == toRef {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// GetPreviousContributors gets authors who had commits until the ref. Empty fromRef means no commits.
+func (c *Command) GetPreviousContributors(fromRef string) ([]Contributor, error) {
+	if fromRef == "" {
+		return []Contributor{}, nil // No Tag
 	}
 
-	format := "--pretty=format:- %an: %b"
-	out, err := exec.Command("git", "log", "--merges", "--first-parent", format, fromTag+toTag).CombinedOutput()
+	out, err := exec.Command("git", "log", contributorLogFormat, fromRef).Output()
 	if err != nil {
-		return "", errors.New(string(out))
+		return nil, commandError(out, err)
+	}
+
+	return parseContributorLog(string(out)), nil
+}
+
+// GetRangeStat gets the statistics of commits from the ref to the ref. Empty fromRef means the root commit.
+func (c *Command) GetRangeStat(fromRef string, toRef string) (RangeStat, error) {
+	stat := RangeStat{FromTag: fromRef}
+
+	revRange := toRef
+	if stat.FromTag != "" {
+		revRange = stat.FromTag + ".." + toRef
+	}
+
+	out, err := exec.Command("git", "rev-list", "--count", revRange).Output()
+	if err != nil {
+		return stat, commandError(out, err)
+	}
+	stat.Commits, err = strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return stat, err
+	}
+
+	if stat.FromTag == "" {
+		return stat, nil // No Tag
+	}
+
+	out, err = exec.Command("git", "diff", "--shortstat", stat.FromTag, toRef).Output()
+	if err != nil {
+		return stat, commandError(out, err)
+	}
+	parseShortStat(string(out), &stat)
+
+	return stat, nil
+}
+
+// GetRemoteURL gets remote(origin) repository's URL.
+func (c *Command) GetRemoteURL() (string, error) {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", commandError(out, err)
 	}
 
 	return strings.TrimRight(string(out), "\n"), nil
 }
 
-// GetLatestTag gets lastest tag name.
-func (c *Command) GetLatestTag() string {
-	out, err := exec.Command("git", "describe", "--abbrev=0", "--tags").CombinedOutput()
+// GetLatestTag gets lastest tag name which has the prefix. Empty prefix means any tag.
+// The tags matching the exclude patterns(e.g. api/* of the component's tags) are ignored.
+func (c *Command) GetLatestTag(prefix string, excludes []string) string {
+	args := []string{"describe", "--abbrev=0", "--tags"}
+	if prefix != "" {
+		args = append(args, "--match", prefix+"*")
+	}
+	for _, e := range excludes {
+		args = append(args, "--exclude", e)
+	}
+
+	out, err := exec.Command("git", args...).CombinedOutput()
 	if err != nil {
 		return "" // No Tag
 	}
@@ -108,24 +242,340 @@ func (c *Command) GetLatestTag() string {
 	return strings.TrimRight(string(out), "\n")
 }
 
+// CommitFiles commits the files to the current branch of local repository. The commit is pushed by PushBranch.
+// The files are unstaged when the commit failed.
+func (c *Command) CommitFiles(files []string, message string) error {
+	out, err := exec.Command("git", append([]string{"add", "--"}, files...)...).CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	out, err = exec.Command("git", append([]string{"commit", "-m", message, "--"}, files...)...).CombinedOutput()
+	if err != nil {
+		exec.Command("git", append([]string{"reset", "-q", "--"}, files...)...).Run()
+		return errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	return nil
+}
+
+// UndoCommit removes the last commit of the current branch which is not pushed, keeping the other local changes.
+func (c *Command) UndoCommit() error {
+	out, err := exec.Command("git", "reset", "--keep", "HEAD^").CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	return nil
+}
+
+// PushBranch pushes the current branch to remote(origin) repository.
+func (c *Command) PushBranch() error {
+	out, err := exec.Command("git", "push", "origin", "HEAD").CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	return nil
+}
+
 // Deploy adds the tag and push the tag to remote(origin) repository.
+// The local tag is deleted when pushing the tag failed.
 func (c *Command) Deploy(tag string) error {
-	out, err := exec.Command("git", "tag", tag).CombinedOutput()
+	return c.DeployCommit(tag, "HEAD")
+}
+
+// DeployCommit adds the tag to the commit and push the tag to remote(origin) repository.
+// The commit is fetched from remote(origin) repository if it does not exist in local repository(e.g. the merge commit of the pull request).
+// The local tag is deleted when pushing the tag failed.
+func (c *Command) DeployCommit(tag string, commit string) error {
+	if err := exec.Command("git", "cat-file", "-e", commit+"^{commit}").Run(); err != nil {
+		out, err := exec.Command("git", "fetch", "origin", commit).CombinedOutput()
+		if err != nil {
+			return errors.New(strings.TrimRight(string(out), "\n"))
+		}
+	}
+
+	out, err := exec.Command("git", "tag", tag, commit).CombinedOutput()
 	if err != nil {
 		return errors.New(string(out))
 	}
 
 	out, err = exec.Command("git", "push", "origin", tag).CombinedOutput()
 	if err != nil {
-		return errors.New(string(out))
+		pushErr := strings.TrimRight(string(out), "\n")
+		if err := c.DeleteTag(tag, false); err != nil {
+			return fmt.Errorf("%s(rollback failed: %s)", pushErr, err.Error())
+		}
+		return fmt.Errorf("%s(rolled back: deleted local tag %s)", pushErr, tag)
+	}
+
+	return nil
+}
+
+// IsExistBranch checks the branch exist or not in local or remote(origin) repository.
+func (c *Command) IsExistBranch(branch string) bool {
+	if err := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+branch).Run(); err == nil {
+		return true
+	}
+
+	out, err := exec.Command("git", "ls-remote", "--heads", "origin", branch).CombinedOutput()
+	if err != nil {
+		return false
+	}
+
+	return string(out) != ""
+}
+
+// PushReleaseBranch creates the new branch from the current branch, writes the changes and commits them, pushes the branch
+// to remote(origin) repository and switches back to the current branch. It returns the current branch which is the base of the release pull request.
+// When a step after creating the branch failed, it switches back to the current branch, reverts the files and deletes the local branch.
+func (c *Command) PushReleaseBranch(branch string, changes []FileChange, message string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", commandError(out, err)
+	}
+	base := strings.TrimRight(string(out), "\n")
+
+	out, err = exec.Command("git", "checkout", "-b", branch).CombinedOutput()
+	if err != nil {
+		return "", errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	if err := commitReleaseBranch(branch, changes, message); err != nil {
+		if rollbackErr := abandonReleaseBranch(base, branch, changes); rollbackErr != nil {
+			return "", fmt.Errorf("%s(rollback failed: %s)", err.Error(), rollbackErr.Error())
+		}
+		return "", fmt.Errorf("%s(rolled back: switched back to %s and deleted local branch %s)", err.Error(), base, branch)
+	}
+
+	out, err = exec.Command("git", "checkout", base).CombinedOutput()
+	if err != nil {
+		return "", errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	return base, nil
+}
+
+// commitReleaseBranch writes the changes, commits them to the current branch and pushes the branch to remote(origin) repository.
+func commitReleaseBranch(branch string, changes []FileChange, message string) error {
+	if err := WriteFileChanges(changes); err != nil {
+		return err
+	}
+
+	files := []string{}
+	for _, c := range changes {
+		files = append(files, c.Path)
+	}
+	commands := [][]string{
+		append([]string{"add", "--"}, files...),
+		append([]string{"commit", "-m", message, "--"}, files...),
+		{"push", "-u", "origin", branch},
+	}
+	for _, args := range commands {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			return errors.New(strings.TrimRight(string(out), "\n"))
+		}
+	}
+
+	return nil
+}
+
+// abandonReleaseBranch switches back to the base branch keeping the uncommitted changes, reverts them and deletes the release branch.
+func abandonReleaseBranch(base string, branch string, changes []FileChange) error {
+	out, err := exec.Command("git", "checkout", base).CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimRight(string(out), "\n"))
+	}
+	if err := RevertFileChanges(changes); err != nil {
+		return err
+	}
+	out, err = exec.Command("git", "branch", "-D", branch).CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimRight(string(out), "\n"))
+	}
+
+	return nil
+}
+
+// CreatePullRequest creates the pull request from the head branch to the base branch in GitHub.
+func (c *Command) CreatePullRequest(base string, head string, title string, body string) (*PullRequest, error) {
+	return createPullRequest(base, head, title, body)
+}
+
+// GetPullRequest gets the newest pull request of the head branch. It returns nil if the pull request does not exist.
+func (c *Command) GetPullRequest(head string) (*PullRequest, error) {
+	return findPullRequest(head)
+}
+
+// GetComments gets the bodies of the comments of the issue or pull request in GitHub.
+func (c *Command) GetComments(number int) ([]string, error) {
+	return listComments(number)
+}
+
+// AddComment posts the comment on the issue or pull request in GitHub.
+func (c *Command) AddComment(number int, body string) error {
+	_, err := hubAPI("-X", "POST", fmt.Sprintf("repos/{owner}/{repo}/issues/%d/comments", number), "-f", "body="+body)
+	return err
+}
+
+// AddLabels adds the labels to the issue or pull request in GitHub.
+func (c *Command) AddLabels(number int, labels []string) error {
+	_, err := hubAPIInput(map[string][]string{"labels": labels}, "-X", "POST", fmt.Sprintf("repos/{owner}/{repo}/issues/%d/labels", number))
+	return err
+}
+
+// CloseIssue closes the issue in GitHub as completed.
+func (c *Command) CloseIssue(number int) error {
+	_, err := hubAPI("-X", "PATCH", fmt.Sprintf("repos/{owner}/{repo}/issues/%d", number), "-f", "state=closed", "-f", "state_reason=completed")
+	return err
+}
+
+// GetMilestone gets the milestone of the title in GitHub. It returns nil if the milestone does not exist.
+func (c *Command) GetMilestone(title string) (*Milestone, error) {
+	return findMilestone(title)
+}
+
+// ListMilestonePullRequests lists the pull requests of the milestone in GitHub.
+func (c *Command) ListMilestonePullRequests(number int) ([]PullRequest, error) {
+	return listMilestonePullRequests(number)
+}
+
+// CloseMilestone closes the milestone in GitHub.
+func (c *Command) CloseMilestone(number int) error {
+	_, err := hubAPI("-X", "PATCH", fmt.Sprintf("repos/{owner}/{repo}/milestones/%d", number), "-f", "state=closed")
+	return err
+}
+
+// CreateMilestone creates the milestone of the title in GitHub.
+func (c *Command) CreateMilestone(title string) error {
+	_, err := hubAPI("-X", "POST", "repos/{owner}/{repo}/milestones", "-f", "title="+title)
+	return err
+}
+
+// CreateDeployment creates the deployment of the ref to the environment in GitHub. It returns the deployment's ID.
+func (c *Command) CreateDeployment(ref string, environment string, description string) (int, error) {
+	body := map[string]interface{}{
+		"ref":               ref,
+		"environment":       environment,
+		"description":       description,
+		"auto_merge":        false,
+		"required_contexts": []string{},
+	}
+	out, err := hubAPIInput(body, "-X", "POST", "repos/{owner}/{repo}/deployments")
+	if err != nil {
+		return 0, err
+	}
+
+	var deployment Deployment
+	if err := json.Unmarshal(out, &deployment); err != nil {
+		return 0, err
+	}
+	if deployment.ID == 0 {
+		return 0, fmt.Errorf("deployment of %s is not created: %s", ref, strings.TrimSpace(string(out)))
+	}
+
+	return deployment.ID, nil
+}
+
+// SetDeploymentStatus sets the state(e.g. success or failure) of the deployment in GitHub.
+func (c *Command) SetDeploymentStatus(id int, state string) error {
+	_, err := hubAPI("-X", "POST", fmt.Sprintf("repos/{owner}/{repo}/deployments/%d/statuses", id), "-f", "state="+state)
+	return err
+}
+
+// ListDeployments lists the recent deployments in GitHub in order of newest first.
+func (c *Command) ListDeployments() ([]Deployment, error) {
+	return listDeployments()
+}
+
+// GetDeploymentState gets the latest state of the deployment in GitHub. Empty means the deployment has no status.
+func (c *Command) GetDeploymentState(id int) (string, error) {
+	return getDeploymentState(id)
+}
+
+// DeleteTag deletes the tag from local repository, and from remote(origin) repository if remote is true.
+func (c *Command) DeleteTag(tag string, remote bool) error {
+	if remote {
+		out, err := exec.Command("git", "push", "--delete", "origin", "refs/tags/"+tag).CombinedOutput()
+		if err != nil {
+			return errors.New(strings.TrimRight(string(out), "\n"))
+		}
+	}
+
+	out, err := exec.Command("git", "tag", "--delete", tag).CombinedOutput()
+	if err != nil {
+		return errors.New(strings.TrimRight(string(out), "\n"))
 	}
 
 	return nil
 }
 
 // Publish creates the release note in Github.
-func (c *Command) Publish(tag string, message string) error {
-	out, err := exec.Command("hub", "release", "create", "-m", message, tag).CombinedOutput()
+// The "latest" marker is set in the same request as the creation, so the release is not left without it.
+func (c *Command) Publish(tag string, message string, options PublishOptions) error {
+	// draft release is marked as "latest" when it's finalized.
+	if options.Latest != "" && !options.Draft {
+		return createRelease(tag, message, options)
+	}
+
+	args := []string{"release", "create", "-m", message}
+	if options.Draft {
+		args = append(args, "--draft")
+	}
+	if options.Prerelease {
+		args = append(args, "--prerelease")
+	}
+
+	out, err := exec.Command("hub", append(args, tag)...).CombinedOutput()
+	if err != nil {
+		return errors.New(string(out))
+	}
+
+	return nil
+}
+
+// FinalizeRelease publishes the draft release of the tag.
+func (c *Command) FinalizeRelease(tag string, latest string) error {
+	release, err := findRelease(tag)
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		return fmt.Errorf("release of %s is not found", tag)
+	}
+	if !release.Draft {
+		return fmt.Errorf("release of %s is not draft", tag)
+	}
+
+	fields := map[string]string{"draft": "false"}
+	if latest != "" {
+		fields["make_latest"] = latest
+	}
+
+	return updateRelease(release.ID, fields)
+}
+
+// GetPreviousTag gets the newest tag before toRef which has the same scheme and prefix as the tag.
+// Tags of other schemes or prefixes(e.g. hotfix_20180525 for release_20180525) are ignored.
+func (c *Command) GetPreviousTag(tag string, toRef string, scheme TagScheme) string {
+	out, err := exec.Command("git", "tag", "--merged", toRef+"^", "--sort=-creatordate").Output()
+	if err != nil {
+		return "" // No Tag
+	}
+
+	return previousTag(scheme, tag, strings.Split(strings.TrimSpace(string(out)), "\n"))
+}
+
+// GetRelease gets the release of the tag. It returns nil if the release does not exist.
+func (c *Command) GetRelease(tag string) (*Release, error) {
+	return findRelease(tag)
+}
+
+// UpdateRelease updates the title and body of the release.
+func (c *Command) UpdateRelease(tag string, message string) error {
+	out, err := exec.Command("hub", "release", "edit", "-m", message, tag).CombinedOutput()
 	if err != nil {
 		return errors.New(string(out))
 	}
@@ -133,6 +583,48 @@ func (c *Command) Publish(tag string, message string) error {
 	return nil
 }
 
+// UploadAssets uploads the files to the release of the tag. Failed uploads are retried.
+func (c *Command) UploadAssets(tag string, files []string) error {
+	release, err := findRelease(tag)
+	if err != nil {
+		return err
+	}
+	if release == nil {
+		return fmt.Errorf("release of %s is not found", tag)
+	}
+
+	for _, file := range files {
+		err := retry(uploadAttempts, retryInterval, func() error {
+			return uploadAsset(release, file)
+		})
+		if err != nil {
+			return fmt.Errorf("uploading %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// ListTags lists tags in order of newest first. When mergedInto is not empty, only tags reachable from it are listed.
+func (c *Command) ListTags(mergedInto string) ([]Tag, error) {
+	args := []string{"for-each-ref", "--sort=-creatordate", tagRefFormat}
+	if mergedInto != "" {
+		args = append(args, "--merged", mergedInto)
+	}
+
+	out, err := exec.Command("git", append(args, "refs/tags")...).Output()
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+
+	return parseTagRefs(string(out)), nil
+}
+
+// ListReleases lists all releases in GitHub.
+func (c *Command) ListReleases() ([]Release, error) {
+	return listReleases()
+}
+
 func availableCommand(name string) error {
 	out, err := exec.Command(name, "--version").CombinedOutput()
 	if err != nil {
@@ -146,18 +638,30 @@ func availableCommand(name string) error {
 	return nil
 }
 
-func isExistsCredential() bool {
-	u, _ := user.Current()
-	_, err := os.Stat(u.HomeDir + "/.config/hub")
+// commandError converts the error of exec.Cmd.Output into the error having stderr's message.
+func commandError(out []byte, err error) error {
+	if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+		return errors.New(strings.TrimRight(string(exitErr.Stderr), "\n"))
+	}
+	if len(out) > 0 {
+		return errors.New(string(out))
+	}
 
-	return err == nil
+	return err
 }
 
-func getPreviousTag(tag string) string {
-	out, err := exec.Command("git", "describe", "--abbrev=0", "--tags", tag+"^").CombinedOutput()
-	if err != nil {
-		return "" // No Tag
+// pathArgs returns git's options limiting commits to the paths.
+func pathArgs(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
 	}
 
-	return strings.TrimRight(string(out), "\n")
+	return append([]string{"--"}, paths...)
+}
+
+func isExistsCredential() bool {
+	u, _ := user.Current()
+	_, err := os.Stat(u.HomeDir + "/.config/hub")
+
+	return err == nil
 }
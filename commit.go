@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CommitStrategy decides which commits are picked up for the release note.
+type CommitStrategy string
+
+// Commit strategy.
+const (
+	// StrategyMerges picks merge commits on the first-parent history(merge commit workflow).
+	StrategyMerges CommitStrategy = "merges"
+	// StrategyFirstParent picks all commits on the first-parent history(squash merge workflow).
+	StrategyFirstParent CommitStrategy = "first-parent"
+	// StrategyNoMerges picks all non-merge commits(rebase merge workflow).
+	StrategyNoMerges CommitStrategy = "no-merges"
+)
+
+// ParseCommitStrategy converts the name into CommitStrategy. Empty name means StrategyMerges.
+func ParseCommitStrategy(name string) (CommitStrategy, error) {
+	switch s := CommitStrategy(name); s {
+	case "":
+		return StrategyMerges, nil
+	case StrategyMerges, StrategyFirstParent, StrategyNoMerges:
+		return s, nil
+	}
+
+	return "", fmt.Errorf("unknown commit strategy %q(supported: %s, %s, %s)", name, StrategyMerges, StrategyFirstParent, StrategyNoMerges)
+}
+
+// logArgs returns git log's options selecting commits for the strategy.
+func (s CommitStrategy) logArgs() []string {
+	switch s {
+	case StrategyFirstParent:
+		return []string{"--first-parent"}
+	case StrategyNoMerges:
+		return []string{"--no-merges"}
+	default:
+		return []string{"--merges", "--first-parent"}
+	}
+}
+
+// Commit is the commit which is listed in the release note.
+type Commit struct {
+	Hash   string
+	Author string
+	Email  string
+	Title  string
+	Body   string
+	// PR is the pull request number. 0 means unknown.
+	PR int
+}
+
+// Separators of commitLogFormat's fields and records.
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
+// commitLogFormat is git log's format which parseCommitLog can parse.
+// The author's name and email respect .mailmap.
+const commitLogFormat = "--pretty=format:%H%x1f%P%x1f%aN%x1f%aE%x1f%s%x1f%b%x1e"
+
+var (
+	mergePullRequestRe  = regexp.MustCompile(`^Merge pull request #(\d+) `)
+	squashPullRequestRe = regexp.MustCompile(`^(.*?)\s*\(#(\d+)\)$`)
+)
+
+// parseCommitLog parses git log's output formatted with commitLogFormat.
+func parseCommitLog(out string) []Commit {
+	commits := []Commit{}
+	for _, record := range strings.Split(out, recordSeparator) {
+		record = strings.Trim(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSeparator, 6)
+		if len(fields) < 6 {
+			continue
+		}
+
+		isMerge := len(strings.Fields(fields[1])) > 1
+		commits = append(commits, newCommit(fields[0], isMerge, fields[2], fields[3], fields[4], fields[5]))
+	}
+
+	return commits
+}
+
+func newCommit(hash string, isMerge bool, author, email, subject, body string) Commit {
+	c := Commit{Hash: hash, Author: author, Email: email, Title: subject, Body: strings.TrimSpace(body)}
+
+	// merge commit of GitHub has the pull request's title in body.
+	if isMerge {
+		if m := mergePullRequestRe.FindStringSubmatch(subject); m != nil {
+			c.PR, _ = strconv.Atoi(m[1])
+		}
+		if title := firstLine(body); title != "" {
+			c.Title = title
+		}
+		return c
+	}
+
+	// squash merge of GitHub adds the pull request's number to the end of subject.
+	if m := squashPullRequestRe.FindStringSubmatch(subject); m != nil {
+		c.Title = m[1]
+		c.PR, _ = strconv.Atoi(m[2])
+	}
+
+	return c
+}
+
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+
+	return ""
+}
+
+// UniqueCommits removes the commits which are duplicated in the commits or in seen commits.
+// The commits are identified by the pull request number, or by the author and title.
+func UniqueCommits(commits []Commit, seen []Commit) []Commit {
+	keys := map[string]bool{}
+	key := func(c Commit) string {
+		if c.PR != 0 {
+			return "#" + strconv.Itoa(c.PR)
+		}
+		return c.Author + "\x00" + c.Title
+	}
+	for _, c := range seen {
+		keys[key(c)] = true
+	}
+
+	unique := []Commit{}
+	for _, c := range commits {
+		if keys[key(c)] {
+			continue
+		}
+		keys[key(c)] = true
+		unique = append(unique, c)
+	}
+
+	return unique
+}
+
+// PullRequestNumbers returns the unique pull request numbers of the commits in order of the commits.
+func PullRequestNumbers(commits []Commit) []int {
+	numbers := []int{}
+	seen := map[int]bool{}
+	for _, c := range commits {
+		if c.PR == 0 || seen[c.PR] {
+			continue
+		}
+		seen[c.PR] = true
+		numbers = append(numbers, c.PR)
+	}
+
+	return numbers
+}
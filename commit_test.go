@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCommitStrategy(t *testing.T) {
+	type pattern struct {
+		exp  CommitStrategy
+		name string
+	}
+	patterns := []pattern{
+		{StrategyMerges, ""},
+		{StrategyMerges, "merges"},
+		{StrategyFirstParent, "first-parent"},
+		{StrategyNoMerges, "no-merges"},
+	}
+
+	for _, p := range patterns {
+		strategy, err := ParseCommitStrategy(p.name)
+		if err != nil {
+			t.Errorf("Error=%q, Name=%q", err.Error(), p.name)
+		}
+		if strategy != p.exp {
+			t.Errorf("Output=%q, Expected=%q", strategy, p.exp)
+		}
+	}
+}
+
+func TestParseCommitStrategy_Error(t *testing.T) {
+	_, err := ParseCommitStrategy("squash")
+
+	expected := "unknown commit strategy"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%q, Expected=%q", err.Error(), expected)
+	}
+}
+
+func TestParseCommitLog(t *testing.T) {
+	records := []string{
+		// merge commit
+		"a1\x1fp1 p2\x1fitosho\x1fitosho@example.com\x1fMerge pull request #12 from itosho/feature\x1fadd feature\n\x1e",
+		// squash merge
+		"\nb2\x1fp1\x1fkazu\x1fkazu@example.com\x1ffix bug (#34)\x1f* fix bug\n* add test\n\x1e",
+		// rebase merge
+		"\nc3\x1fp1\x1fkazu\x1fkazu@example.com\x1fupdate README\x1f\x1e",
+	}
+	commits := parseCommitLog(strings.Join(records, ""))
+
+	expected := []Commit{
+		{Hash: "a1", Author: "itosho", Email: "itosho@example.com", Title: "add feature", Body: "add feature", PR: 12},
+		{Hash: "b2", Author: "kazu", Email: "kazu@example.com", Title: "fix bug", Body: "* fix bug\n* add test", PR: 34},
+		{Hash: "c3", Author: "kazu", Email: "kazu@example.com", Title: "update README"},
+	}
+	if !reflect.DeepEqual(commits, expected) {
+		t.Errorf("Output=%v, Expected=%v", commits, expected)
+	}
+}
+
+func TestParseCommitLog_Empty(t *testing.T) {
+	commits := parseCommitLog("")
+	if len(commits) != 0 {
+		t.Errorf("Output=%v, Expected=empty", commits)
+	}
+}
+
+func TestUniqueCommits(t *testing.T) {
+	commits := []Commit{
+		{Author: "itosho", Title: "fix bug", PR: 2},
+		{Author: "kazu", Title: "fix bug(cherry-pick)", PR: 2},
+		{Author: "kazu", Title: "update README"},
+		{Author: "kazu", Title: "update README"},
+		{Author: "kazu", Title: "add feature", PR: 3},
+	}
+	seen := []Commit{{Author: "kazu", Title: "add feature", PR: 3}}
+	unique := UniqueCommits(commits, seen)
+
+	expected := []Commit{
+		{Author: "itosho", Title: "fix bug", PR: 2},
+		{Author: "kazu", Title: "update README"},
+	}
+	if !reflect.DeepEqual(unique, expected) {
+		t.Errorf("Output=%v, Expected=%v", unique, expected)
+	}
+}
+
+func TestPullRequestNumbers(t *testing.T) {
+	commits := []Commit{{PR: 12}, {PR: 0}, {PR: 10}, {PR: 12}}
+
+	expected := []int{12, 10}
+	if numbers := PullRequestNumbers(commits); !reflect.DeepEqual(numbers, expected) {
+		t.Errorf("Output=%v, Expected=%v", numbers, expected)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ComponentConfig configures the component of monorepo.
+type ComponentConfig struct {
+	// Paths are the directories or files of the component. Empty means the whole repository.
+	Paths []string `json:"paths"`
+	// VersionFiles are the files embedding the component's version. They replace the repository's version files.
+	VersionFiles []VersionFileConfig `json:"version_files"`
+}
+
+// Component is the service in monorepo which is tagged with its name as prefix(e.g. api/v1.2.3).
+// Zero value means the whole repository.
+type Component struct {
+	Name         string
+	Paths        []string
+	VersionFiles []VersionFileConfig
+}
+
+// NewComponent creates the component configured in the project config. Empty name means the whole repository.
+func NewComponent(name string, configs map[string]ComponentConfig) (Component, error) {
+	if name == "" {
+		return Component{}, nil
+	}
+
+	config, ok := configs[name]
+	if !ok {
+		names := []string{}
+		for n := range configs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Component{}, fmt.Errorf("unknown component %q(configured: %s)", name, strings.Join(names, ", "))
+	}
+
+	return Component{Name: name, Paths: config.Paths, VersionFiles: config.VersionFiles}, nil
+}
+
+// Prefix returns the prefix of the component's tags.
+func (c Component) Prefix() string {
+	if c.Name == "" {
+		return ""
+	}
+
+	return c.Name + "/"
+}
+
+// Owns checks the tag belongs to the component.
+func (c Component) Owns(tag string) bool {
+	return strings.HasPrefix(tag, c.Prefix())
+}
+
+// NextVersion gets next version of the latest tag by the scheme. The first tag of the component has its prefix.
+func (c Component) NextVersion(latestTag string, scheme TagScheme) (string, error) {
+	next, err := scheme.Next(latestTag)
+	if err != nil {
+		return "", err
+	}
+	if latestTag == "" {
+		return c.Prefix() + next, nil
+	}
+
+	return next, nil
+}
+
+// isComponentTag checks the tag belongs to any of the configured components.
+func isComponentTag(tag string, configs map[string]ComponentConfig) bool {
+	for name := range configs {
+		if (Component{Name: name}).Owns(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// componentTagPatterns returns the glob patterns matching the tags of the configured components(e.g. api/*) in order of name.
+func componentTagPatterns(configs map[string]ComponentConfig) []string {
+	patterns := []string{}
+	for name := range configs {
+		patterns = append(patterns, (Component{Name: name}).Prefix()+"*")
+	}
+	sort.Strings(patterns)
+
+	return patterns
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewComponent(t *testing.T) {
+	configs := map[string]ComponentConfig{
+		"api": {Paths: []string{"services/api", "lib"}},
+		"web": {Paths: []string{"services/web"}},
+	}
+
+	component, err := NewComponent("api", configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Component{Name: "api", Paths: []string{"services/api", "lib"}}
+	if !reflect.DeepEqual(component, expected) {
+		t.Errorf("Output=%v, Expected=%v", component, expected)
+	}
+
+	component, err = NewComponent("", configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(component, Component{}) {
+		t.Errorf("Output=%v, Expected=%v", component, Component{})
+	}
+}
+
+func TestNewComponent_Unknown(t *testing.T) {
+	_, err := NewComponent("batch", map[string]ComponentConfig{"web": {}, "api": {}})
+
+	expected := `unknown component "batch"(configured: api, web)`
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
+
+func TestComponent_Owns(t *testing.T) {
+	type pattern struct {
+		exp       bool
+		component Component
+		tag       string
+	}
+	patterns := []pattern{
+		{true, Component{Name: "api"}, "api/v1.2.3"},
+		{false, Component{Name: "api"}, "web/v1.2.3"},
+		{false, Component{Name: "api"}, "v1.2.3"},
+		{true, Component{}, "v1.2.3"},
+		{true, Component{}, "api/v1.2.3"},
+	}
+
+	for _, p := range patterns {
+		if p.component.Owns(p.tag) != p.exp {
+			t.Errorf("Output=%t, Expected=%t, Component=%q, Tag=%q", p.component.Owns(p.tag), p.exp, p.component.Name, p.tag)
+		}
+	}
+}
+
+func TestComponent_NextVersion(t *testing.T) {
+	type pattern struct {
+		exp       string
+		component Component
+		tag       string
+	}
+	patterns := []pattern{
+		{"api/v1.2.4", Component{Name: "api"}, "api/v1.2.3"},
+		{"api/v1.0.0", Component{Name: "api"}, ""},
+		{"v1.0.0", Component{}, ""},
+	}
+
+	for _, p := range patterns {
+		next, err := p.component.NextVersion(p.tag, autoScheme{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Tag=%q", next, p.exp, p.tag)
+		}
+	}
+}
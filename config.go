@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ConfigFile is the project config's file name. It is put on the root of the repository.
+const ConfigFile = ".gdp.json"
+
+// Config is the project config. Zero value means gdp's default behavior.
+type Config struct {
+	// Strategy is the commit strategy for the release note(merges, first-parent or no-merges).
+	Strategy string `json:"strategy"`
+	// Contributors adds the contributors section to the release note.
+	Contributors bool `json:"contributors"`
+	// Header configures the header of the release note.
+	Header HeaderConfig `json:"header"`
+	// Issues configures the references to the issue tracker.
+	Issues IssuesConfig `json:"issues"`
+	// Notifications are notified after deploy or publish succeeded.
+	Notifications []NotificationConfig `json:"notifications"`
+	// Assets are glob patterns of files uploaded as release assets.
+	Assets []string `json:"assets"`
+	// Rollback deletes the pushed tag when a later step of release failed.
+	Rollback bool `json:"rollback"`
+	// Components are the services of monorepo tagged with their name as prefix(e.g. api/v1.2.3).
+	Components map[string]ComponentConfig `json:"components"`
+	// Scheme is the tag scheme(semver, date, calver, counter or the registered one).
+	// Empty means calver when calver.layout is set, otherwise semver or date by the latest tag's format.
+	Scheme string `json:"scheme"`
+	// CalVer configures the calendar versioning.
+	CalVer CalVerConfig `json:"calver"`
+	// Counter configures the counter scheme.
+	Counter CounterConfig `json:"counter"`
+	// LatestTag configures how the latest tag is resolved.
+	LatestTag LatestTagConfig `json:"latest_tag"`
+	// VersionFiles configures the files embedding the version which are updated and committed on deploy.
+	VersionFiles VersionFilesConfig `json:"version_files"`
+	// ReleasePR configures the release pull request created by prepare.
+	ReleasePR ReleasePRConfig `json:"release_pr"`
+	// Annotate configures the annotation of the pull requests included in the published release.
+	Annotate AnnotateConfig `json:"annotate"`
+	// Milestone configures the milestones named after the tags.
+	Milestone MilestoneConfig `json:"milestone"`
+	// Environments are the environments which promote moves the tags between by their prefixes.
+	Environments map[string]EnvironmentConfig `json:"environments"`
+}
+
+// HeaderConfig configures the header of the release note.
+type HeaderConfig struct {
+	// Compare adds the link comparing previous tag with the tag.
+	Compare bool `json:"compare"`
+	// Stats adds the count of commits, files changed and lines added/removed.
+	Stats bool `json:"stats"`
+}
+
+// How the latest tag is resolved.
+const (
+	LatestTagByDescribe = "describe"
+	LatestTagByVersion  = "version"
+)
+
+// LatestTagConfig configures how the latest tag is resolved.
+type LatestTagConfig struct {
+	// By is "describe"(the nearest tag reachable from HEAD, default) or "version"(the highest version of the tag scheme).
+	By string `json:"by"`
+	// Reachable limits the tags to the ones reachable from HEAD when By is "version".
+	Reachable bool `json:"reachable"`
+}
+
+// LoadConfig reads the project config. It returns zero value if the file does not exist.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	if err := json.Unmarshal(b, &config); err != nil {
+		return config, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// IssuesConfig configures the references to the issue tracker.
+type IssuesConfig struct {
+	Patterns []IssuePattern `json:"patterns"`
+	// Export is the file exporting referenced issues as JSON.
+	Export string `json:"export"`
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFile)
+	if err := os.WriteFile(path, []byte(`{"strategy": "first-parent"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "first-parent"
+	if config.Strategy != expected {
+		t.Errorf("Output=%q, Expected=%q", config.Strategy, expected)
+	}
+}
+
+func TestLoadConfig_NotExist(t *testing.T) {
+	config, err := LoadConfig(filepath.Join(t.TempDir(), ConfigFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Strategy != "" {
+		t.Errorf("Output=%q, Expected=%q", config.Strategy, "")
+	}
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ConfigFile)
+	if err := os.WriteFile(path, []byte(`{"strategy": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := LoadConfig(path)
+
+	expected := "invalid config file"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Contributor is the author of commits in the release.
+type Contributor struct {
+	Name  string
+	Email string
+	// Login is GitHub's login name. Empty means unknown.
+	Login string
+	// FirstTime means the contributor has no commits before the previous tag.
+	FirstTime bool
+}
+
+// contributorLogFormat is git log's format which parseContributorLog can parse.
+const contributorLogFormat = "--pretty=format:%aN%x1f%aE"
+
+// noreplyEmailRe matches GitHub's noreply email(e.g. 12345+itosho@users.noreply.github.com).
+var noreplyEmailRe = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.github\.com$`)
+
+// NewContributor creates the contributor. Login is recovered from GitHub's noreply email.
+func NewContributor(name string, email string) Contributor {
+	c := Contributor{Name: name, Email: email}
+	if m := noreplyEmailRe.FindStringSubmatch(strings.ToLower(email)); m != nil {
+		c.Login = m[1]
+	}
+
+	return c
+}
+
+// keys are the identities of the contributor compared case-insensitively. The same person may commit with the regular email
+// and GitHub's noreply email, so GitHub's login is compared as the name as well as the name itself.
+func (c Contributor) keys() []string {
+	keys := []string{}
+	if c.Email != "" {
+		keys = append(keys, "email:"+strings.ToLower(c.Email))
+	}
+	for _, name := range []string{c.Name, c.Login} {
+		if name != "" {
+			keys = append(keys, "name:"+strings.ToLower(name))
+		}
+	}
+
+	return keys
+}
+
+// GetContributors gets unique authors of commits in order of appearance. The authors having any same identity are the same.
+// The contributor not in past contributors is marked as first-time contributor. The author without email(e.g. the pull request
+// of the milestone which is not found in git log) is not marked, because the past contributors are identified by git log.
+func GetContributors(commits []Commit, past []Contributor) []Contributor {
+	known := map[string]bool{}
+	for _, c := range past {
+		for _, k := range c.keys() {
+			known[k] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	contributors := []Contributor{}
+	for _, commit := range commits {
+		c := NewContributor(commit.Author, commit.Email)
+		keys := c.keys()
+		if containsKey(seen, keys) {
+			continue
+		}
+		for _, k := range keys {
+			seen[k] = true
+		}
+
+		c.FirstTime = c.Email != "" && !containsKey(known, keys)
+		contributors = append(contributors, c)
+	}
+
+	return contributors
+}
+
+func containsKey(set map[string]bool, keys []string) bool {
+	for _, k := range keys {
+		if set[k] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseContributorLog parses git log's output formatted with contributorLogFormat.
+func parseContributorLog(out string) []Contributor {
+	contributors := []Contributor{}
+	for _, line := range strings.Split(out, "\n") {
+		name, email, ok := strings.Cut(line, fieldSeparator)
+		if !ok {
+			continue
+		}
+
+		contributors = append(contributors, NewContributor(name, email))
+	}
+
+	return contributors
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewContributor(t *testing.T) {
+	type pattern struct {
+		exp   string
+		email string
+	}
+	patterns := []pattern{
+		{"itosho", "12345+itosho@users.noreply.github.com"},
+		{"itosho", "itosho@users.noreply.github.com"},
+		{"", "itosho@example.com"},
+	}
+
+	for _, p := range patterns {
+		c := NewContributor("itosho", p.email)
+		if c.Login != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Email=%q", c.Login, p.exp, p.email)
+		}
+	}
+}
+
+func TestGetContributors(t *testing.T) {
+	commits := []Commit{
+		{Author: "itosho", Email: "itosho@example.com", Title: "initial commit"},
+		{Author: "kazu", Email: "1+kazu@users.noreply.github.com", Title: "add feature"},
+		{Author: "Itosho", Email: "ITOSHO@example.com", Title: "fix bug"},
+		{Author: "Kazu", Email: "kazu@users.noreply.github.com", Title: "fix typo"},
+	}
+	past := []Contributor{
+		NewContributor("itosho", "itosho@example.com"),
+	}
+	contributors := GetContributors(commits, past)
+
+	expected := []Contributor{
+		{Name: "itosho", Email: "itosho@example.com"},
+		{Name: "kazu", Email: "1+kazu@users.noreply.github.com", Login: "kazu", FirstTime: true},
+	}
+	if !reflect.DeepEqual(contributors, expected) {
+		t.Errorf("Output=%v, Expected=%v", contributors, expected)
+	}
+}
+
+func TestGetContributors_NoreplyEmail(t *testing.T) {
+	commits := []Commit{
+		{Author: "Itosho Kato", Email: "1+itosho@users.noreply.github.com", Title: "fix bug"},
+		{Author: "Kazu", Email: "2+kazu@users.noreply.github.com", Title: "add feature"},
+		// the author of the milestone's pull request without email is not marked.
+		{Author: "sota", Title: "fix typo"},
+	}
+	// the past contributors committed with the regular email.
+	past := []Contributor{
+		NewContributor("Itosho Kato", "itosho@example.com"),
+		NewContributor("kazu", "kazu@example.com"),
+	}
+	contributors := GetContributors(commits, past)
+
+	for _, c := range contributors {
+		if c.FirstTime {
+			t.Errorf("%s is marked as first-time contributor", c.Name)
+		}
+	}
+	if len(contributors) != 3 {
+		t.Errorf("Output=%v, Expected=%d contributors", contributors, 3)
+	}
+}
+
+func TestParseContributorLog(t *testing.T) {
+	contributors := parseContributorLog("itosho\x1fitosho@example.com\nkazu\x1fkazu@users.noreply.github.com")
+
+	expected := []Contributor{
+		{Name: "itosho", Email: "itosho@example.com"},
+		{Name: "kazu", Email: "kazu@users.noreply.github.com", Login: "kazu"},
+	}
+	if !reflect.DeepEqual(contributors, expected) {
+		t.Errorf("Output=%v, Expected=%v", contributors, expected)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sort"
+)
+
+// Deployment states of GitHub.
+const (
+	DeploymentSuccess = "success"
+	DeploymentFailure = "failure"
+)
+
+// Deployment is the deployment in GitHub.
+type Deployment struct {
+	ID          int    `json:"id"`
+	SHA         string `json:"sha"`
+	Ref         string `json:"ref"`
+	Environment string `json:"environment"`
+}
+
+// CurrentDeployments finds the latest successful deployment of each environment.
+// The deployments are newest first, and state gets the latest state of the deployment.
+func CurrentDeployments(deployments []Deployment, state func(id int) (string, error)) (map[string]Deployment, error) {
+	current := map[string]Deployment{}
+	for _, d := range deployments {
+		if _, ok := current[d.Environment]; ok {
+			continue
+		}
+
+		s, err := state(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		if s == DeploymentSuccess {
+			current[d.Environment] = d
+		}
+	}
+
+	return current, nil
+}
+
+// DeployedEnvironments returns the environments which the tag is deployed to currently in order of name.
+func DeployedEnvironments(current map[string]Deployment, tag Tag) []string {
+	environments := []string{}
+	for env, d := range current {
+		if d.Ref == tag.Name || (d.SHA != "" && d.SHA == tag.Commit) {
+			environments = append(environments, env)
+		}
+	}
+	sort.Strings(environments)
+
+	return environments
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCurrentDeployments(t *testing.T) {
+	deployments := []Deployment{
+		{ID: 4, SHA: "ccc", Environment: "production"},
+		{ID: 3, SHA: "bbb", Environment: "staging"},
+		{ID: 2, SHA: "bbb", Environment: "production"},
+		{ID: 1, SHA: "aaa", Environment: "production"},
+	}
+	states := map[int]string{4: DeploymentFailure, 3: DeploymentSuccess, 2: DeploymentSuccess, 1: DeploymentSuccess}
+	requested := []int{}
+
+	current, err := CurrentDeployments(deployments, func(id int) (string, error) {
+		requested = append(requested, id)
+		return states[id], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]Deployment{"production": deployments[2], "staging": deployments[1]}
+	if !reflect.DeepEqual(current, expected) {
+		t.Errorf("Output=%+v, Expected=%+v", current, expected)
+	}
+	// the older deployments of the found environments are not requested.
+	if !reflect.DeepEqual(requested, []int{4, 3, 2}) {
+		t.Errorf("Requested=%v, Expected=%v", requested, []int{4, 3, 2})
+	}
+}
+
+func TestCurrentDeployments_Error(t *testing.T) {
+	_, err := CurrentDeployments([]Deployment{{ID: 1}}, func(id int) (string, error) {
+		return "", errors.New("404 Not Found")
+	})
+
+	if err == nil || err.Error() != "404 Not Found" {
+		t.Errorf("Output=%v, Expected=%q", err, "404 Not Found")
+	}
+}
+
+func TestDeployedEnvironments(t *testing.T) {
+	current := map[string]Deployment{
+		"production": {SHA: "aaa"},
+		"staging":    {SHA: "bbb"},
+		"qa":         {Ref: "v1.2.4"},
+		"dev":        {SHA: "bbb"},
+	}
+
+	type pattern struct {
+		exp []string
+		tag Tag
+	}
+	patterns := []pattern{
+		{[]string{"dev", "qa", "staging"}, Tag{Name: "v1.2.4", Commit: "bbb"}},
+		{[]string{"production"}, Tag{Name: "v1.2.3", Commit: "aaa"}},
+		{[]string{}, Tag{Name: "v1.2.2", Commit: "ccc"}},
+	}
+
+	for _, p := range patterns {
+		if environments := DeployedEnvironments(current, p.tag); !reflect.DeepEqual(environments, p.exp) {
+			t.Errorf("Output=%v, Expected=%v", environments, p.exp)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	before := "## v1.2.3\n- itosho: initial commit\n- itosho: fix bug"
+	after := "## v1.2.3\n- itosho: initial commit\n- kazu: add feature\n- itosho: fix bug (#12)"
+	diff := DiffLines(before, after)
+
+	expected := "  ## v1.2.3\n"
+	expected = expected + "  - itosho: initial commit\n"
+	expected = expected + "- - itosho: fix bug\n"
+	expected = expected + "+ - kazu: add feature\n"
+	expected = expected + "+ - itosho: fix bug (#12)"
+	if diff != expected {
+		t.Errorf("Output=%q, Expected=%q", diff, expected)
+	}
+}
+
+func TestNormalizeNote(t *testing.T) {
+	note := normalizeNote("Release v1.2.3\r\n\r\n## v1.2.3 \r\n- itosho: fix bug\r\n")
+
+	expected := "Release v1.2.3\n\n## v1.2.3\n- itosho: fix bug"
+	if note != expected {
+		t.Errorf("Output=%q, Expected=%q", note, expected)
+	}
+}
@@ -1,53 +1,103 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
-	"time"
 )
 
-// GetNextVersion gets next version according to the tag of the format.
+// GetNextVersion gets next version according to the tag of the format(semantic or date).
+// It fails when the tag is neither semantic nor date format.
 func GetNextVersion(tag string) (string, error) {
-	if tag == "" {
-		return "v1.0.0", nil
-	}
+	return autoScheme{}.Next(tag)
+}
 
-	tags := strings.Split(tag, ".")
-	len := len(tags)
+var prereleaseRe = regexp.MustCompile(`\d+\.\d+\.\d+-[0-9A-Za-z.-]+(\+[0-9A-Za-z.-]+)?$`)
 
-	// semantic version(e.g. v1.2.3)
-	if len > 2 {
-		patch, err := strconv.Atoi(tags[len-1])
-		if err != nil {
-			return "", err
-		}
+// IsPrerelease checks the tag has SemVer's pre-release suffix(e.g. v1.2.3-rc.1).
+func IsPrerelease(tag string) bool {
+	return prereleaseRe.MatchString(tag)
+}
+
+// GetReleaseNote formats commits list.
+func GetReleaseNote(tag string, commits []Commit) string {
+	return ReleaseNote{Tag: tag, Commits: commits}.String()
+}
 
-		tags[len-1] = strconv.Itoa(patch + 1)
-		return strings.Join(tags, "."), nil
+// ReleaseNote is the content of the release note.
+type ReleaseNote struct {
+	Tag string
+	// Header is put between the tag's heading and commits list.
+	Header  []string
+	Commits []Commit
+	// Groups are commits list grouped by intermediate tags. Commits are ignored when Groups are set.
+	Groups []ReleaseNoteGroup
+	// Sections are put after commits list.
+	Sections []string
+}
+
+// ReleaseNoteGroup is commits list of the intermediate tag.
+type ReleaseNoteGroup struct {
+	Tag     string
+	Commits []Commit
+}
+
+// String formats the release note. The first line is the release's title.
+func (n ReleaseNote) String() string {
+	note := "Release " + n.Tag + "\n\n"
+	if len(n.Groups) == 0 {
+		note = note + "## " + n.Tag + "\n"
+	}
+	for _, h := range n.Header {
+		note = note + h + "\n\n"
 	}
 
-	// date version(e.g. 20180525.1 or release_20180525.1)
-	const layout = "20060102"
-	today := time.Now().Format(layout)
+	if len(n.Groups) == 0 {
+		note = note + FormatCommitList(n.Commits)
+	}
+	for i, g := range n.Groups {
+		if i > 0 {
+			note = note + "\n\n"
+		}
+		note = note + "## " + g.Tag + "\n" + FormatCommitList(g.Commits)
+	}
+
+	for _, s := range n.Sections {
+		note = note + "\n\n" + s
+	}
 
-	dateRe := regexp.MustCompile(`(.*)(\d{8})\.(.+)`)
-	if m := dateRe.FindStringSubmatch(tag); m != nil {
-		if m[2] == today {
-			minor, err := strconv.Atoi(m[3])
-			if err != nil {
-				return "", err
-			}
+	return note
+}
 
-			next := strconv.Itoa(minor + 1)
-			return m[1] + today + "." + next, nil
+// FormatCommitList formats commits list as markdown's list.
+func FormatCommitList(commits []Commit) string {
+	lines := make([]string, 0, len(commits))
+	for _, c := range commits {
+		line := fmt.Sprintf("- %s: %s", c.Author, c.Title)
+		if c.PR != 0 {
+			line = fmt.Sprintf("%s (#%d)", line, c.PR)
 		}
-		return m[1] + today + "." + "1", nil
+		lines = append(lines, line)
 	}
-	return today + ".1", nil
+
+	return strings.Join(lines, "\n")
 }
 
-// GetReleaseNote formats merge-commits list.
-func GetReleaseNote(tag string, list string) string {
-	return "Release " + tag + "\n\n" + "## " + tag + "\n" + list
+// FormatContributors formats contributors as markdown's section.
+func FormatContributors(contributors []Contributor) string {
+	lines := []string{"## Contributors"}
+	for _, c := range contributors {
+		name := c.Name
+		if c.Login != "" {
+			name = "@" + c.Login
+		}
+
+		line := "- " + name
+		if c.FirstTime {
+			line = line + " (first-time contributor)"
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
 }
@@ -25,7 +25,7 @@ func TestGetNextVersion_Semantic(t *testing.T) {
 func TestGetNextVersion_SemanticError(t *testing.T) {
 	_, err := GetNextVersion("4.2.semantic")
 
-	expected := "invalid syntax"
+	expected := "is neither semantic(e.g. v1.2.3) nor date(e.g. 20180525.1) format"
 	if !strings.Contains(err.Error(), expected) {
 		t.Errorf("Output=%q, Expected=%q", err.Error(), expected)
 	}
@@ -82,7 +82,7 @@ func TestGetNextVersion_DateError(t *testing.T) {
 	today := time.Now().Format(layout)
 	_, err := GetNextVersion(today + ".date")
 
-	expected := "invalid syntax"
+	expected := "is neither semantic(e.g. v1.2.3) nor date(e.g. 20180525.1) format"
 	if !strings.Contains(err.Error(), expected) {
 		t.Errorf("Output=%q, Expected=%q", err.Error(), expected)
 	}
@@ -96,10 +96,31 @@ func TestGetNextVersion_EmptyTag(t *testing.T) {
 	}
 }
 
+func TestIsPrerelease(t *testing.T) {
+	type pattern struct {
+		exp bool
+		tag string
+	}
+	patterns := []pattern{
+		{true, "v1.2.3-rc.1"},
+		{true, "1.2.3-beta"},
+		{true, "v1.2.3-alpha.1+build.5"},
+		{false, "v1.2.3"},
+		{false, "20180525.1"},
+		{false, "release-42"},
+	}
+
+	for _, p := range patterns {
+		if IsPrerelease(p.tag) != p.exp {
+			t.Errorf("Output=%t, Expected=%t, Tag=%q", IsPrerelease(p.tag), p.exp, p.tag)
+		}
+	}
+}
+
 func TestGetReleaseNote(t *testing.T) {
 	list := "- itosho: initial commit\n"
 	list = list + "- itosho: fix bug"
-	note := GetReleaseNote("20180525.1", list)
+	note := GetReleaseNote("20180525.1", fakeCommits)
 
 	expected := "Release 20180525.1\n\n"
 	expected = expected + "## 20180525.1\n"
@@ -109,3 +130,81 @@ func TestGetReleaseNote(t *testing.T) {
 		t.Errorf("Output=%q, Expected=%q", note, expected)
 	}
 }
+
+func TestFormatCommitList_PullRequest(t *testing.T) {
+	commits := []Commit{
+		{Author: "itosho", Title: "add feature", PR: 12},
+		{Author: "itosho", Title: "fix typo"},
+	}
+	list := FormatCommitList(commits)
+
+	expected := "- itosho: add feature (#12)\n"
+	expected = expected + "- itosho: fix typo"
+	if list != expected {
+		t.Errorf("Output=%q, Expected=%q", list, expected)
+	}
+}
+
+func TestFormatContributors(t *testing.T) {
+	contributors := []Contributor{
+		{Name: "itosho"},
+		{Name: "kazu", Login: "kazu", FirstTime: true},
+	}
+	section := FormatContributors(contributors)
+
+	expected := "## Contributors\n"
+	expected = expected + "- itosho\n"
+	expected = expected + "- @kazu (first-time contributor)"
+	if section != expected {
+		t.Errorf("Output=%q, Expected=%q", section, expected)
+	}
+}
+
+func TestReleaseNote_String(t *testing.T) {
+	note := ReleaseNote{
+		Tag:      "v1.2.4",
+		Header:   []string{"**Full Changelog**: https://github.com/Connehito/gdp/compare/v1.2.3...v1.2.4"},
+		Commits:  fakeCommits,
+		Sections: []string{"## Contributors\n- itosho"},
+	}
+
+	expected := "Release v1.2.4\n\n"
+	expected = expected + "## v1.2.4\n"
+	expected = expected + "**Full Changelog**: https://github.com/Connehito/gdp/compare/v1.2.3...v1.2.4\n\n"
+	expected = expected + "- itosho: initial commit\n"
+	expected = expected + "- itosho: fix bug\n\n"
+	expected = expected + "## Contributors\n- itosho"
+	if note.String() != expected {
+		t.Errorf("Output=%q, Expected=%q", note.String(), expected)
+	}
+}
+
+func TestReleaseNote_StringGroups(t *testing.T) {
+	note := ReleaseNote{
+		Tag:    "v1.4.0...v1.9.2",
+		Header: []string{"**Full Changelog**: https://github.com/Connehito/gdp/compare/v1.4.0...v1.9.2"},
+		Groups: []ReleaseNoteGroup{
+			{Tag: "v1.9.2", Commits: []Commit{{Author: "kazu", Title: "add feature"}}},
+			{Tag: "v1.5.0", Commits: []Commit{{Author: "itosho", Title: "fix bug"}}},
+		},
+	}
+
+	expected := "Release v1.4.0...v1.9.2\n\n"
+	expected = expected + "**Full Changelog**: https://github.com/Connehito/gdp/compare/v1.4.0...v1.9.2\n\n"
+	expected = expected + "## v1.9.2\n"
+	expected = expected + "- kazu: add feature\n\n"
+	expected = expected + "## v1.5.0\n"
+	expected = expected + "- itosho: fix bug"
+	if note.String() != expected {
+		t.Errorf("Output=%q, Expected=%q", note.String(), expected)
+	}
+}
+
+func TestGetNextVersion_Unknown(t *testing.T) {
+	_, err := GetNextVersion("v1.2")
+
+	expected := `latest tag "v1.2" is neither semantic(e.g. v1.2.3) nor date(e.g. 20180525.1) format`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
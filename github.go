@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Release is the release in GitHub.
+type Release struct {
+	ID         int     `json:"id"`
+	TagName    string  `json:"tag_name"`
+	Name       string  `json:"name"`
+	Body       string  `json:"body"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	HTMLURL    string  `json:"html_url"`
+	UploadURL  string  `json:"upload_url"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset is the file attached to the release.
+type Asset struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Message returns the release's title and body in the same format as the release note.
+func (r Release) Message() string {
+	if r.Body == "" {
+		return r.Name
+	}
+
+	return r.Name + "\n\n" + r.Body
+}
+
+// hubAPI calls GitHub's REST API via hub command. {owner} and {repo} in the endpoint are replaced by hub.
+func hubAPI(args ...string) ([]byte, error) {
+	out, err := exec.Command("hub", append([]string{"api"}, args...)...).Output()
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+
+	return out, nil
+}
+
+// hubAPIInput calls GitHub's REST API via hub command with the JSON request body.
+func hubAPIInput(body interface{}, args ...string) ([]byte, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("hub", append([]string{"api", "--input", "-"}, args...)...)
+	cmd.Stdin = bytes.NewReader(b)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(out, err)
+	}
+
+	return out, nil
+}
+
+// findRelease finds the release(including draft) of the tag. It returns nil if the release does not exist.
+func findRelease(tag string) (*Release, error) {
+	var found *Release
+	err := eachReleasePage(func(releases []Release) bool {
+		for _, r := range releases {
+			if r.TagName == tag {
+				found = &r
+				return false
+			}
+		}
+		return true
+	})
+
+	return found, err
+}
+
+// listReleases lists all releases(including draft).
+func listReleases() ([]Release, error) {
+	all := []Release{}
+	err := eachReleasePage(func(releases []Release) bool {
+		all = append(all, releases...)
+		return true
+	})
+
+	return all, err
+}
+
+// eachReleasePage calls fn with each page of releases until fn returns false.
+func eachReleasePage(fn func(releases []Release) bool) error {
+	for page := 1; ; page++ {
+		out, err := hubAPI(fmt.Sprintf("repos/{owner}/{repo}/releases?per_page=100&page=%d", page))
+		if err != nil {
+			return err
+		}
+
+		var releases []Release
+		if err := json.Unmarshal(out, &releases); err != nil {
+			return err
+		}
+		if !fn(releases) || len(releases) < 100 {
+			return nil
+		}
+	}
+}
+
+// updateRelease updates the release's fields.
+func updateRelease(id int, fields map[string]string) error {
+	args := []string{"-X", "PATCH", fmt.Sprintf("repos/{owner}/{repo}/releases/%d", id)}
+	for k, v := range fields {
+		if k == "draft" || k == "prerelease" {
+			args = append(args, "-F", k+"="+v)
+			continue
+		}
+		args = append(args, "-f", k+"="+v)
+	}
+
+	_, err := hubAPI(args...)
+	return err
+}
+
+// createRelease creates the release of the tag with the message's first line as the title and the options in one request.
+func createRelease(tag string, message string, options PublishOptions) error {
+	name, body, _ := strings.Cut(message, "\n")
+	request := map[string]interface{}{
+		"tag_name":    tag,
+		"name":        name,
+		"body":        strings.TrimLeft(body, "\n"),
+		"draft":       options.Draft,
+		"prerelease":  options.Prerelease,
+		"make_latest": options.Latest,
+	}
+
+	_, err := hubAPIInput(request, "-X", "POST", "repos/{owner}/{repo}/releases")
+	return err
+}
+
+// createPullRequest creates the pull request from the head branch to the base branch.
+func createPullRequest(base string, head string, title string, body string) (*PullRequest, error) {
+	out, err := hubAPI("-X", "POST", "repos/{owner}/{repo}/pulls", "-f", "base="+base, "-f", "head="+head, "-f", "title="+title, "-f", "body="+body)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PullRequest
+	if err := json.Unmarshal(out, &pr); err != nil {
+		return nil, err
+	}
+
+	return &pr, nil
+}
+
+// findPullRequest finds the newest pull request(including closed) of the head branch. It returns nil if it does not exist.
+func findPullRequest(head string) (*PullRequest, error) {
+	out, err := hubAPI("repos/{owner}/{repo}/pulls?state=all&head={owner}:" + url.QueryEscape(head))
+	if err != nil {
+		return nil, err
+	}
+
+	var prs []PullRequest
+	if err := json.Unmarshal(out, &prs); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, nil
+	}
+
+	return &prs[0], nil
+}
+
+// listComments lists the bodies of all comments of the issue or pull request.
+func listComments(number int) ([]string, error) {
+	bodies := []string{}
+	for page := 1; ; page++ {
+		out, err := hubAPI(fmt.Sprintf("repos/{owner}/{repo}/issues/%d/comments?per_page=100&page=%d", number, page))
+		if err != nil {
+			return nil, err
+		}
+
+		var comments []struct {
+			Body string `json:"body"`
+		}
+		if err := json.Unmarshal(out, &comments); err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			bodies = append(bodies, c.Body)
+		}
+		if len(comments) < 100 {
+			return bodies, nil
+		}
+	}
+}
+
+// findMilestone finds the milestone(including closed) of the title. It returns nil if the milestone does not exist.
+func findMilestone(title string) (*Milestone, error) {
+	for page := 1; ; page++ {
+		out, err := hubAPI(fmt.Sprintf("repos/{owner}/{repo}/milestones?state=all&per_page=100&page=%d", page))
+		if err != nil {
+			return nil, err
+		}
+
+		var milestones []Milestone
+		if err := json.Unmarshal(out, &milestones); err != nil {
+			return nil, err
+		}
+		for _, m := range milestones {
+			if m.Title == title {
+				return &m, nil
+			}
+		}
+		if len(milestones) < 100 {
+			return nil, nil
+		}
+	}
+}
+
+// listMilestonePullRequests lists the pull requests(including closed) of the milestone.
+func listMilestonePullRequests(number int) ([]PullRequest, error) {
+	prs := []PullRequest{}
+	for page := 1; ; page++ {
+		out, err := hubAPI(fmt.Sprintf("repos/{owner}/{repo}/issues?milestone=%d&state=all&per_page=100&page=%d", number, page))
+		if err != nil {
+			return nil, err
+		}
+
+		// the issues API returns the issues and the pull requests.
+		var issues []struct {
+			PullRequest
+			Link *struct {
+				MergedAt *time.Time `json:"merged_at"`
+			} `json:"pull_request"`
+		}
+		if err := json.Unmarshal(out, &issues); err != nil {
+			return nil, err
+		}
+		for _, i := range issues {
+			if i.Link == nil {
+				continue
+			}
+			pr := i.PullRequest
+			pr.MergedAt = i.Link.MergedAt
+			prs = append(prs, pr)
+		}
+		if len(issues) < 100 {
+			return prs, nil
+		}
+	}
+}
+
+// listDeployments lists the deployments in order of newest first. Only the first page is listed to limit the requests.
+func listDeployments() ([]Deployment, error) {
+	out, err := hubAPI("repos/{owner}/{repo}/deployments?per_page=100")
+	if err != nil {
+		return nil, err
+	}
+
+	var deployments []Deployment
+	if err := json.Unmarshal(out, &deployments); err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
+
+// getDeploymentState gets the latest state of the deployment. Empty means the deployment has no status.
+func getDeploymentState(id int) (string, error) {
+	out, err := hubAPI(fmt.Sprintf("repos/{owner}/{repo}/deployments/%d/statuses?per_page=1", id))
+	if err != nil {
+		return "", err
+	}
+
+	var statuses []struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(out, &statuses); err != nil {
+		return "", err
+	}
+	if len(statuses) == 0 {
+		return "", nil
+	}
+
+	return statuses[0].State, nil
+}
+
+// uploadAsset uploads the file to the release. The asset having the same name is replaced.
+func uploadAsset(release *Release, file string) error {
+	name := filepath.Base(file)
+	assets := []Asset{}
+	for _, a := range release.Assets {
+		if a.Name != name {
+			assets = append(assets, a)
+			continue
+		}
+		if _, err := hubAPI("-X", "DELETE", fmt.Sprintf("repos/{owner}/{repo}/releases/assets/%d", a.ID)); err != nil {
+			return err
+		}
+	}
+	release.Assets = assets
+
+	// upload_url is the URI template(e.g. https://uploads.github.com/repos/o/r/releases/1/assets{?name,label}).
+	uploadURL := release.UploadURL
+	if i := strings.Index(uploadURL, "{"); i >= 0 {
+		uploadURL = uploadURL[:i]
+	}
+	uploadURL = uploadURL + "?name=" + url.QueryEscape(name)
+
+	_, err := hubAPI("-X", "POST", "-H", "Content-Type: application/octet-stream", "--input", file, uploadURL)
+	return err
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IssuePattern configures the reference to the issue tracker(e.g. Jira, Linear or GitHub issues).
+type IssuePattern struct {
+	// Name is the issue tracker's name.
+	Name string `json:"name"`
+	// Pattern is the regular expression matching the reference. The first group(or whole match) is the issue ID.
+	Pattern string `json:"pattern"`
+	// URL is the issue's URL. {id} is replaced with the issue ID.
+	URL string `json:"url"`
+}
+
+// IssueTracker extracts the references to the issue tracker.
+type IssueTracker struct {
+	name string
+	re   *regexp.Regexp
+	url  string
+}
+
+// NewIssueTrackers compiles the issue patterns.
+func NewIssueTrackers(patterns []IssuePattern) ([]IssueTracker, error) {
+	trackers := make([]IssueTracker, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid issue pattern of %s: %w", p.Name, err)
+		}
+		trackers = append(trackers, IssueTracker{name: p.Name, re: re, url: p.URL})
+	}
+
+	return trackers, nil
+}
+
+// Issue is the issue referenced in the release.
+type Issue struct {
+	Tracker string `json:"tracker"`
+	ID      string `json:"id"`
+	// Ref is the reference text(e.g. PROJ-123 or #456).
+	Ref string `json:"ref"`
+	URL string `json:"url,omitempty"`
+}
+
+// issueMatch is the position of the reference in the text.
+type issueMatch struct {
+	start, end int
+	issue      Issue
+}
+
+// findIssues finds the references in the text. Overlapped references are picked by the order of trackers.
+func findIssues(text string, trackers []IssueTracker) []issueMatch {
+	matches := []issueMatch{}
+	for _, t := range trackers {
+		for _, loc := range t.re.FindAllStringSubmatchIndex(text, -1) {
+			ref := text[loc[0]:loc[1]]
+			id := ref
+			if len(loc) > 3 && loc[2] >= 0 {
+				id = text[loc[2]:loc[3]]
+			}
+
+			url := ""
+			if t.url != "" {
+				url = strings.ReplaceAll(t.url, "{id}", id)
+			}
+
+			m := issueMatch{start: loc[0], end: loc[1], issue: Issue{Tracker: t.name, ID: id, Ref: ref, URL: url}}
+			if !overlaps(matches, m) {
+				matches = append(matches, m)
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+
+	return matches
+}
+
+func overlaps(matches []issueMatch, m issueMatch) bool {
+	for _, o := range matches {
+		if m.start < o.end && o.start < m.end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExtractIssues gets unique issues referenced in title and body of commits.
+func ExtractIssues(commits []Commit, trackers []IssueTracker) []Issue {
+	seen := map[string]bool{}
+	issues := []Issue{}
+	for _, c := range commits {
+		for _, m := range findIssues(c.Title+"\n"+c.Body, trackers) {
+			key := m.issue.Tracker + "\x00" + m.issue.ID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			issues = append(issues, m.issue)
+		}
+	}
+
+	return issues
+}
+
+// LinkIssues turns the references in commits' title into markdown's links.
+func LinkIssues(commits []Commit, trackers []IssueTracker) []Commit {
+	linked := make([]Commit, 0, len(commits))
+	for _, c := range commits {
+		c.Title = linkIssues(c.Title, trackers)
+		linked = append(linked, c)
+	}
+
+	return linked
+}
+
+func linkIssues(text string, trackers []IssueTracker) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range findIssues(text, trackers) {
+		if m.issue.URL == "" {
+			continue
+		}
+
+		b.WriteString(text[last:m.start])
+		b.WriteString(formatIssue(m.issue))
+		last = m.end
+	}
+	b.WriteString(text[last:])
+
+	return b.String()
+}
+
+func formatIssue(issue Issue) string {
+	if issue.URL == "" {
+		return issue.Ref
+	}
+
+	return "[" + issue.Ref + "](" + issue.URL + ")"
+}
+
+// FormatIssues formats issues as markdown's section.
+func FormatIssues(issues []Issue) string {
+	lines := []string{"## Issues resolved"}
+	for _, issue := range issues {
+		lines = append(lines, "- "+formatIssue(issue))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ExportIssues writes issues referenced in the release to the file as JSON.
+func ExportIssues(path string, tag string, issues []Issue) error {
+	b, err := json.MarshalIndent(struct {
+		Tag    string  `json:"tag"`
+		Issues []Issue `json:"issues"`
+	}{tag, issues}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
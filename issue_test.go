@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fakeIssuePatterns = []IssuePattern{
+	{Name: "jira", Pattern: `\b([A-Z][A-Z0-9]+-\d+)\b`, URL: "https://connehito.atlassian.net/browse/{id}"},
+	{Name: "github", Pattern: `#(\d+)\b`, URL: "https://github.com/Connehito/gdp/issues/{id}"},
+}
+
+func TestNewIssueTrackers_Error(t *testing.T) {
+	_, err := NewIssueTrackers([]IssuePattern{{Name: "jira", Pattern: `(PROJ-\d+`}})
+
+	expected := "invalid issue pattern of jira"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
+
+func TestExtractIssues(t *testing.T) {
+	trackers, _ := NewIssueTrackers(fakeIssuePatterns)
+	commits := []Commit{
+		{Author: "itosho", Title: "PROJ-123 add feature", Body: "PROJ-123 add feature\n\nfix #456 and PROJ-7"},
+		{Author: "kazu", Title: "fix bug", Body: "related to PROJ-123", PR: 12},
+	}
+	issues := ExtractIssues(commits, trackers)
+
+	expected := []Issue{
+		{Tracker: "jira", ID: "PROJ-123", Ref: "PROJ-123", URL: "https://connehito.atlassian.net/browse/PROJ-123"},
+		{Tracker: "github", ID: "456", Ref: "#456", URL: "https://github.com/Connehito/gdp/issues/456"},
+		{Tracker: "jira", ID: "PROJ-7", Ref: "PROJ-7", URL: "https://connehito.atlassian.net/browse/PROJ-7"},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("Output=%v, Expected=%v", issues, expected)
+	}
+}
+
+func TestLinkIssues(t *testing.T) {
+	trackers, _ := NewIssueTrackers(fakeIssuePatterns)
+	commits := []Commit{
+		{Author: "itosho", Title: "PROJ-123 fix #456"},
+		{Author: "kazu", Title: "fix bug"},
+	}
+	linked := LinkIssues(commits, trackers)
+
+	expected := "[PROJ-123](https://connehito.atlassian.net/browse/PROJ-123) fix [#456](https://github.com/Connehito/gdp/issues/456)"
+	if linked[0].Title != expected {
+		t.Errorf("Output=%q, Expected=%q", linked[0].Title, expected)
+	}
+	if linked[1].Title != "fix bug" {
+		t.Errorf("Output=%q, Expected=%q", linked[1].Title, "fix bug")
+	}
+	if commits[0].Title != "PROJ-123 fix #456" {
+		t.Errorf("Original commit is changed: %q", commits[0].Title)
+	}
+}
+
+func TestFormatIssues(t *testing.T) {
+	issues := []Issue{
+		{Tracker: "jira", ID: "PROJ-123", Ref: "PROJ-123", URL: "https://connehito.atlassian.net/browse/PROJ-123"},
+		{Tracker: "github", ID: "456", Ref: "#456"},
+	}
+	section := FormatIssues(issues)
+
+	expected := "## Issues resolved\n"
+	expected = expected + "- [PROJ-123](https://connehito.atlassian.net/browse/PROJ-123)\n"
+	expected = expected + "- #456"
+	if section != expected {
+		t.Errorf("Output=%q, Expected=%q", section, expected)
+	}
+}
+
+func TestExportIssues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issues.json")
+	issues := []Issue{{Tracker: "github", ID: "456", Ref: "#456"}}
+	if err := ExportIssues(path, "v1.2.4", issues); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "tag": "v1.2.4",
+  "issues": [
+    {
+      "tracker": "github",
+      "id": "456",
+      "ref": "#456"
+    }
+  ]
+}
+`
+	if string(b) != expected {
+		t.Errorf("Output=%q, Expected=%q", string(b), expected)
+	}
+}
@@ -12,23 +12,61 @@ const Version string = "v0.2.6"
 const Usage string = `gdp is a CLI tool for pushing the tag associated with deployment and publishing the release note in GitHub.
 
 Usage:
-  gdp <command> [-t | --tag <TAG>] [-d | --dry-run] [-f | --force]
+  gdp <command> [flags]
 
 Available Commands:
   deploy   Add the tag to local repository and push the tag to remote(origin) repository
   publish  Create the release note in GitHub which based on the merge commits of the tag
+  release  Deploy and publish in one step with the same release note
+  list     Show the release history of tags
+  status   Show what the next deploy would ship without changing anything
+  diff     Show the release note between any two tags
+  prepare  Open the release pull request having the changelog and the version files of the tag
+  finalize Add the tag to the merge commit of the release pull request and publish its body
+  promote  Add the tag of the environment to the commit of the latest tag of the other environment(e.g. staging to production)
 
 Flags:
-  -d, --dry-run  dry-run gdp
-  -t, --tag      specify tag at semantic(e.g. v1.2.3 or 1.2.3) or date(e.g. 20180525.1 or release_20180525) format
-  -f, --force    run gdp without validation
-  -h, --help     help for gdp
-  -v, --version  confirm gdp version
+  -d, --dry-run      dry-run gdp
+  -t, --tag          specify tag at semantic(e.g. v1.2.3 or 1.2.3) or date(e.g. 20180525.1 or release_20180525) format
+  -f, --force        run gdp without validation
+  --strategy         select commits for the release note: merges(default), first-parent(squash merge) or no-merges(rebase merge)
+  --export-issues    export issues referenced in the release note to the file as JSON
+  --draft            publish the release as draft
+  --prerelease       publish the release as pre-release(enabled automatically when the tag has pre-release suffix e.g. v1.2.3-rc.1)
+  --latest           mark the release as "latest": true, false or legacy
+  --finalize         publish the draft release of the tag after review
+  --update           update the existing release of the tag with the regenerated release note
+  --asset            upload files matching the glob pattern as release assets with checksums.txt(can be specified multiple times)
+  --rollback         delete the pushed tag when a later step of release failed
+  --milestone        select the pull requests of the release note by the tag's milestone instead of the merge commits
+  --annotate         comment "Released in TAG" on the pull requests of the release note after publish
+  --environment      record the deploy as the GitHub deployment to the environment(e.g. production)
+  --from             the environment of promote whose latest tag is promoted(e.g. staging)
+  --to               the environment of promote which the tag is promoted to(e.g. production)
+  --limit            the number of tags shown by list(default 10)
+  --since            generate the release note since the ref instead of previous tag
+  --since-date       show tags created since the date(YYYY-MM-DD) by list
+  --json             output list as JSON
+  --group            group the release note of diff by intermediate tags
+  --allow-downgrade  deploy the tag which is not greater than the latest tag
+  --component        limit tags and commits to the component of monorepo configured in .gdp.json(e.g. api for api/v1.2.3)
+  -h, --help         help for gdp
+  -v, --version      confirm gdp version
 
 Example Usage:
-  gdp deploy -t TAG -d   specify tag and dry-run
-  gdp publish -t TAG -f  force(skipped validation)
-  gdp deploy/publish     set tag automatically
+  gdp deploy -t TAG -d                        specify tag and dry-run
+  gdp publish -t TAG -f                       force(skipped validation)
+  gdp publish -t TAG --draft                  publish as draft
+  gdp publish -t TAG --finalize               publish the draft after review
+  gdp publish -t TAG --update                 regenerate the existing release
+  gdp publish -t TAG --asset 'dist/*.tar.gz'  upload release assets
+  gdp release -t TAG                          deploy and publish
+  gdp list --limit 20 --json                  show the release history as JSON
+  gdp diff v1.4.0 v1.9.2 --group              show the release note between the tags
+  gdp prepare -t TAG                          open the release pull request
+  gdp finalize -t TAG                         tag and publish the merged release pull request
+  gdp promote --from staging --to production  tag the commit of the latest staging tag for production
+  gdp deploy/publish                          set tag automatically
 
 Further Help:
   https://github.com/Connehito/gdp`
@@ -39,10 +77,16 @@ func main() {
 		log.Fatal(err)
 	}
 
+	config, err := LoadConfig(ConfigFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	cli := &CLI{
 		outStream: os.Stdout,
 		errStream: os.Stderr,
 		gdp:       gdp,
+		config:    config,
 	}
 
 	os.Exit(cli.Run(os.Args))
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// MilestoneConfig configures the milestones in GitHub named after the tags.
+type MilestoneConfig struct {
+	// Enabled selects the pull requests of the release note by the tag's milestone. Same as --milestone option.
+	Enabled bool `json:"enabled"`
+	// Title is the title of the tag's milestone. {tag} and {version} are replaced. Empty means {tag}.
+	Title string `json:"title"`
+	// Close closes the tag's milestone after publish.
+	Close bool `json:"close"`
+	// CreateNext creates the milestone of the next version after publish.
+	CreateNext bool `json:"create_next"`
+}
+
+// Milestone is the milestone in GitHub.
+type Milestone struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	State   string `json:"state"`
+	HTMLURL string `json:"html_url"`
+}
+
+// MilestoneTitle returns the title of the tag's milestone by the format.
+func MilestoneTitle(format string, tag string, version string) string {
+	if format == "" {
+		format = "{tag}"
+	}
+
+	return strings.NewReplacer("{tag}", tag, "{version}", version).Replace(format)
+}
+
+// MilestoneCommits converts the merged pull requests of the milestone into the commits of the release note.
+// The pull requests are in order of the merged date, newest first as git log. The hash and the author's email,
+// which the pull requests of the issues API do not have, are taken from the commits in the range of the same pull request.
+func MilestoneCommits(prs []PullRequest, inRange []Commit) []Commit {
+	byPR := map[int]Commit{}
+	for _, c := range inRange {
+		if _, ok := byPR[c.PR]; c.PR != 0 && !ok {
+			byPR[c.PR] = c
+		}
+	}
+
+	merged := []PullRequest{}
+	for _, pr := range prs {
+		if pr.Merged() {
+			merged = append(merged, pr)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].MergedAt.After(*merged[j].MergedAt)
+	})
+
+	commits := []Commit{}
+	for _, pr := range merged {
+		commit := Commit{Hash: pr.MergeCommitSHA, Author: pr.User.Login, Title: pr.Title, Body: pr.Body, PR: pr.Number}
+		if c, ok := byPR[pr.Number]; ok {
+			commit.Hash, commit.Email = c.Hash, c.Email
+		}
+		commits = append(commits, commit)
+	}
+
+	return commits
+}
+
+// CompareMilestone compares the pull requests merged in the range with the ones of the milestone.
+// It returns the pull requests missing from the milestone, and the ones of the milestone missing from the range or not merged.
+func CompareMilestone(commits []Commit, prs []PullRequest) ([]int, []int) {
+	inMilestone := map[int]bool{}
+	for _, pr := range prs {
+		inMilestone[pr.Number] = true
+	}
+	inRange := map[int]bool{}
+	missing := []int{}
+	for _, n := range PullRequestNumbers(commits) {
+		inRange[n] = true
+		if !inMilestone[n] {
+			missing = append(missing, n)
+		}
+	}
+
+	extra := []int{}
+	for _, pr := range prs {
+		if !inRange[pr.Number] {
+			extra = append(extra, pr.Number)
+		}
+	}
+	sort.Ints(extra)
+
+	return missing, extra
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMilestoneTitle(t *testing.T) {
+	type pattern struct {
+		exp    string
+		format string
+	}
+	patterns := []pattern{
+		{"v1.2.4", ""},
+		{"Release 1.2.4", "Release {version}"},
+	}
+
+	for _, p := range patterns {
+		if title := MilestoneTitle(p.format, "v1.2.4", "1.2.4"); title != p.exp {
+			t.Errorf("Output=%q, Expected=%q", title, p.exp)
+		}
+	}
+}
+
+func TestMilestoneCommits(t *testing.T) {
+	older := time.Date(2020, 4, 1, 10, 00, 00, 0, time.UTC)
+	newer := time.Date(2020, 4, 2, 10, 00, 00, 0, time.UTC)
+	prs := []PullRequest{
+		{Number: 10, Title: "fix bug", User: GitHubUser{Login: "itosho"}, MergedAt: &older, MergeCommitSHA: "abc"},
+		{Number: 11, Title: "wip"},
+		{Number: 12, Title: "add feature", User: GitHubUser{Login: "kazu"}, MergedAt: &newer, MergeCommitSHA: "def"},
+	}
+
+	inRange := []Commit{{Hash: "123", Author: "Kazu", Email: "kazu@example.com", Title: "Merge pull request #12 from kazu/feature", PR: 12}}
+
+	// #10 is not merged in the range, so it has no email.
+	expected := []Commit{
+		{Hash: "123", Author: "kazu", Email: "kazu@example.com", Title: "add feature", PR: 12},
+		{Hash: "abc", Author: "itosho", Title: "fix bug", PR: 10},
+	}
+	if commits := MilestoneCommits(prs, inRange); !reflect.DeepEqual(commits, expected) {
+		t.Errorf("Output=%+v, Expected=%+v", commits, expected)
+	}
+}
+
+func TestCompareMilestone(t *testing.T) {
+	commits := []Commit{{PR: 10}, {PR: 11}, {PR: 0}}
+	prs := []PullRequest{{Number: 13}, {Number: 10}, {Number: 12}}
+
+	missing, extra := CompareMilestone(commits, prs)
+	if !reflect.DeepEqual(missing, []int{11}) {
+		t.Errorf("Output=%v, Expected=%v", missing, []int{11})
+	}
+	if !reflect.DeepEqual(extra, []int{12, 13}) {
+		t.Errorf("Output=%v, Expected=%v", extra, []int{12, 13})
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// Notification is the message notified after deploy or publish succeeded.
+type Notification struct {
+	Command    string `json:"command"`
+	Tag        string `json:"tag"`
+	Note       string `json:"note"`
+	CompareURL string `json:"compare_url,omitempty"`
+}
+
+// Title returns the notification's title.
+func (n Notification) Title() string {
+	return fmt.Sprintf("gdp %s %s done", n.Command, n.Tag)
+}
+
+// Text returns the notification's body including the release note and the compare link.
+func (n Notification) Text() string {
+	text := n.Note
+	if n.CompareURL != "" {
+		text = text + "\n\n" + n.CompareURL
+	}
+
+	return text
+}
+
+// Notifier sends the notification to the sink.
+type Notifier interface {
+	Name() string
+	Notify(n Notification) error
+}
+
+// Notification type.
+const (
+	NotifierSlack   = "slack"
+	NotifierTeams   = "teams"
+	NotifierWebhook = "webhook"
+	NotifierEmail   = "email"
+)
+
+// NotificationConfig configures the notifier. Environment variables(e.g. ${SLACK_WEBHOOK_URL}) are expanded.
+type NotificationConfig struct {
+	// Type is slack, teams, webhook or email.
+	Type string `json:"type"`
+	// On is the commands notified. Empty means all commands.
+	On []string `json:"on"`
+	// URL is the incoming webhook's URL(slack, teams and webhook).
+	URL string `json:"url"`
+	// Headers are added to the request(webhook).
+	Headers map[string]string `json:"headers"`
+	// SMTP is the SMTP server's address(e.g. smtp.example.com:587).
+	SMTP     string   `json:"smtp"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// notifies checks the notifier is enabled for the command. Release and finalize are regarded as deploy and publish,
+// and promote is regarded as deploy.
+func (c NotificationConfig) notifies(command string) bool {
+	if len(c.On) == 0 {
+		return true
+	}
+	for _, on := range c.On {
+		if on == command {
+			return true
+		}
+		if (command == CommandRelease || command == CommandFinalize) && (on == CommandDeploy || on == CommandPublish) {
+			return true
+		}
+		if command == CommandPromote && on == CommandDeploy {
+			return true
+		}
+	}
+
+	return false
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// NewNotifiers creates the notifiers enabled for the command.
+func NewNotifiers(configs []NotificationConfig, command string) ([]Notifier, error) {
+	notifiers := []Notifier{}
+	for _, c := range configs {
+		n, err := newNotifier(c)
+		if err != nil {
+			return nil, err
+		}
+		if c.notifies(command) {
+			notifiers = append(notifiers, n)
+		}
+	}
+
+	return notifiers, nil
+}
+
+func newNotifier(c NotificationConfig) (Notifier, error) {
+	switch c.Type {
+	case NotifierSlack, NotifierTeams, NotifierWebhook:
+		url := os.ExpandEnv(c.URL)
+		if url == "" {
+			return nil, fmt.Errorf("url of %s notification is required", c.Type)
+		}
+		headers := map[string]string{}
+		for k, v := range c.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		return &WebhookNotifier{kind: c.Type, url: url, headers: headers}, nil
+	case NotifierEmail:
+		if c.SMTP == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("smtp, from and to of %s notification are required", c.Type)
+		}
+		return &EmailNotifier{
+			addr:     os.ExpandEnv(c.SMTP),
+			username: os.ExpandEnv(c.Username),
+			password: os.ExpandEnv(c.Password),
+			from:     c.From,
+			to:       c.To,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown notification type %q", c.Type)
+}
+
+// WebhookNotifier posts the notification to Slack, Microsoft Teams or generic webhook as JSON.
+type WebhookNotifier struct {
+	kind    string
+	url     string
+	headers map[string]string
+}
+
+// Name returns the notifier's type.
+func (w *WebhookNotifier) Name() string {
+	return w.kind
+}
+
+// Notify posts the notification.
+func (w *WebhookNotifier) Notify(n Notification) error {
+	var payload interface{}
+	switch w.kind {
+	case NotifierSlack:
+		payload = map[string]string{"text": "*" + n.Title() + "*\n" + n.Text()}
+	case NotifierTeams:
+		payload = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  n.Title(),
+			"title":    n.Title(),
+			"text":     n.Text(),
+		}
+	default:
+		payload = n
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+
+	return nil
+}
+
+// EmailNotifier sends the notification by SMTP.
+type EmailNotifier struct {
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+// Name returns the notifier's type.
+func (e *EmailNotifier) Name() string {
+	return NotifierEmail
+}
+
+// Notify sends the notification.
+func (e *EmailNotifier) Notify(n Notification) error {
+	var auth smtp.Auth
+	if e.username != "" {
+		host, _, err := net.SplitHostPort(e.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", e.username, e.password, host)
+	}
+
+	msg := "From: " + e.from + "\r\n" +
+		"To: " + strings.Join(e.to, ", ") + "\r\n" +
+		"Subject: " + n.Title() + "\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n" +
+		"\r\n" +
+		strings.ReplaceAll(n.Text(), "\n", "\r\n") + "\r\n"
+
+	return smtp.SendMail(e.addr, auth, e.from, e.to, []byte(msg))
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var fakeNotification = Notification{
+	Command:    CommandPublish,
+	Tag:        "v1.2.4",
+	Note:       "Release v1.2.4\n\n## v1.2.4\n- itosho: fix bug",
+	CompareURL: "https://github.com/Connehito/gdp/compare/v1.2.3...v1.2.4",
+}
+
+// fakeWebhook records the request body which the webhook received.
+func fakeWebhook(t *testing.T, status int) (*httptest.Server, *map[string]interface{}, *http.Header) {
+	t.Helper()
+	body := map[string]interface{}{}
+	header := http.Header{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &body, &header
+}
+
+func TestNewNotifiers(t *testing.T) {
+	configs := []NotificationConfig{
+		{Type: NotifierSlack, URL: "https://hooks.slack.com/services/xxx"},
+		{Type: NotifierWebhook, URL: "https://example.com/hook", On: []string{CommandDeploy}},
+		{Type: NotifierEmail, SMTP: "localhost:25", From: "gdp@example.com", To: []string{"dev@example.com"}},
+	}
+	notifiers, err := NewNotifiers(configs, CommandPublish)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, n := range notifiers {
+		names = append(names, n.Name())
+	}
+	expected := "slack,email"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Output=%q, Expected=%q", strings.Join(names, ","), expected)
+	}
+}
+
+func TestNewNotifiers_Finalize(t *testing.T) {
+	configs := []NotificationConfig{
+		{Type: NotifierSlack, URL: "https://hooks.slack.com/services/xxx", On: []string{CommandPublish}},
+		{Type: NotifierWebhook, URL: "https://example.com/hook", On: []string{CommandPrepare}},
+	}
+	notifiers, err := NewNotifiers(configs, CommandFinalize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notifiers) != 1 || notifiers[0].Name() != "slack" {
+		t.Errorf("Output=%v, Expected=%q", notifiers, "slack")
+	}
+}
+
+func TestNewNotifiers_Promote(t *testing.T) {
+	configs := []NotificationConfig{
+		{Type: NotifierSlack, URL: "https://hooks.slack.com/services/xxx", On: []string{CommandDeploy}},
+		{Type: NotifierWebhook, URL: "https://example.com/hook", On: []string{CommandPublish}},
+	}
+	notifiers, err := NewNotifiers(configs, CommandPromote)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(notifiers) != 1 || notifiers[0].Name() != "slack" {
+		t.Errorf("Output=%v, Expected=%q", notifiers, "slack")
+	}
+}
+
+func TestNewNotifiers_Error(t *testing.T) {
+	type pattern struct {
+		exp    string
+		config NotificationConfig
+	}
+	patterns := []pattern{
+		{"unknown notification type", NotificationConfig{Type: "line"}},
+		{"url of slack notification is required", NotificationConfig{Type: NotifierSlack, URL: "${GDP_TEST_NOT_EXIST}"}},
+		{"smtp, from and to of email notification are required", NotificationConfig{Type: NotifierEmail}},
+	}
+
+	for _, p := range patterns {
+		_, err := NewNotifiers([]NotificationConfig{p.config}, CommandDeploy)
+		if err == nil || !strings.Contains(err.Error(), p.exp) {
+			t.Errorf("Output=%v, Expected=%q", err, p.exp)
+		}
+	}
+}
+
+func TestWebhookNotifier_Slack(t *testing.T) {
+	server, body, _ := fakeWebhook(t, http.StatusOK)
+	n := &WebhookNotifier{kind: NotifierSlack, url: server.URL}
+	if err := n.Notify(fakeNotification); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "*gdp publish v1.2.4 done*\n" + fakeNotification.Note + "\n\n" + fakeNotification.CompareURL
+	if (*body)["text"] != expected {
+		t.Errorf("Output=%q, Expected=%q", (*body)["text"], expected)
+	}
+}
+
+func TestWebhookNotifier_Teams(t *testing.T) {
+	server, body, _ := fakeWebhook(t, http.StatusOK)
+	n := &WebhookNotifier{kind: NotifierTeams, url: server.URL}
+	if err := n.Notify(fakeNotification); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "gdp publish v1.2.4 done"
+	if (*body)["title"] != expected || (*body)["@type"] != "MessageCard" {
+		t.Errorf("Output=%v, Expected=%q", *body, expected)
+	}
+}
+
+func TestWebhookNotifier_Webhook(t *testing.T) {
+	t.Setenv("GDP_TEST_TOKEN", "secret")
+	server, body, header := fakeWebhook(t, http.StatusNoContent)
+	notifiers, _ := NewNotifiers([]NotificationConfig{
+		{Type: NotifierWebhook, URL: server.URL, Headers: map[string]string{"Authorization": "Bearer ${GDP_TEST_TOKEN}"}},
+	}, CommandPublish)
+	if err := notifiers[0].Notify(fakeNotification); err != nil {
+		t.Fatal(err)
+	}
+
+	if (*body)["tag"] != "v1.2.4" || (*body)["compare_url"] != fakeNotification.CompareURL {
+		t.Errorf("Output=%v", *body)
+	}
+	expected := "Bearer secret"
+	if header.Get("Authorization") != expected {
+		t.Errorf("Output=%q, Expected=%q", header.Get("Authorization"), expected)
+	}
+}
+
+func TestWebhookNotifier_Error(t *testing.T) {
+	server, _, _ := fakeWebhook(t, http.StatusInternalServerError)
+	n := &WebhookNotifier{kind: NotifierWebhook, url: server.URL}
+	err := n.Notify(fakeNotification)
+
+	expected := "unexpected status 500"
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
+
+// fakeSMTP serves one SMTP session and sends the received message to the channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		reply("220 localhost fake SMTP")
+
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- data.String()
+					reply("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "DATA":
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	return l.Addr().String(), received
+}
+
+func TestEmailNotifier(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	n := &EmailNotifier{addr: addr, from: "gdp@example.com", to: []string{"dev@example.com"}}
+	if err := n.Notify(fakeNotification); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := <-received
+	for _, expected := range []string{"Subject: gdp publish v1.2.4 done\r\n", "- itosho: fix bug\r\n", fakeNotification.CompareURL} {
+		if !strings.Contains(msg, expected) {
+			t.Errorf("Output=%q, Expected=%q", msg, expected)
+		}
+	}
+}
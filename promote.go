@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EnvironmentConfig configures the environment which promote moves the tags between.
+type EnvironmentConfig struct {
+	// Prefix is the prefix of the environment's tags(e.g. "stg-" of stg-20240101.2). Empty means no prefix.
+	Prefix string `json:"prefix"`
+}
+
+// Environment is the deployment environment tagged with its prefix(e.g. stg-20240101.2 for staging and 20240101.2 for production).
+type Environment struct {
+	Name   string
+	Prefix string
+	// others are the prefixes of the other environments which are longer than the environment's prefix.
+	others []string
+}
+
+// NewEnvironment creates the environment configured in the project config.
+func NewEnvironment(name string, configs map[string]EnvironmentConfig) (Environment, error) {
+	config, ok := configs[name]
+	if !ok {
+		names := []string{}
+		for n := range configs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Environment{}, fmt.Errorf("unknown environment %q(configured: %s)", name, strings.Join(names, ", "))
+	}
+
+	others := []string{}
+	for n, c := range configs {
+		if n != name && len(c.Prefix) > len(config.Prefix) && strings.HasPrefix(c.Prefix, config.Prefix) {
+			others = append(others, c.Prefix)
+		}
+	}
+
+	return Environment{Name: name, Prefix: config.Prefix, others: others}, nil
+}
+
+// Owns checks the tag belongs to the environment. The tags of the other environments having the longer prefix are excluded
+// (e.g. stg-20240101.2 does not belong to production without prefix).
+func (e Environment) Owns(tag string) bool {
+	if !strings.HasPrefix(tag, e.Prefix) {
+		return false
+	}
+	for _, prefix := range e.others {
+		if strings.HasPrefix(tag, prefix) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Scheme returns the tag scheme of the environment's tags, which parses the version after the environment's prefix
+// (e.g. v1.2.3 of stg-v1.2.3 by semver).
+func (e Environment) Scheme(scheme TagScheme) TagScheme {
+	if e.Prefix == "" {
+		return scheme
+	}
+
+	return environmentScheme{TagScheme: scheme, prefix: e.Prefix}
+}
+
+// environmentScheme is the tag scheme whose tags have the environment's prefix before the tag of the scheme.
+type environmentScheme struct {
+	TagScheme
+	prefix string
+}
+
+// Parse implements TagScheme. The environment's prefix is a part of the prefix of the version.
+func (s environmentScheme) Parse(tag string) (TagVersion, error) {
+	if !strings.HasPrefix(tag, s.prefix) {
+		return TagVersion{}, fmt.Errorf("tag %q does not have the environment's prefix %s", tag, s.prefix)
+	}
+
+	v, err := s.TagScheme.Parse(strings.TrimPrefix(tag, s.prefix))
+	if err != nil {
+		return TagVersion{}, err
+	}
+	v.Tag, v.Prefix = tag, s.prefix+v.Prefix
+
+	return v, nil
+}
+
+// Next implements TagScheme.
+func (s environmentScheme) Next(latest string) (string, error) {
+	if latest != "" && !strings.HasPrefix(latest, s.prefix) {
+		return "", fmt.Errorf("latest tag %q does not have the environment's prefix %s", latest, s.prefix)
+	}
+
+	next, err := s.TagScheme.Next(strings.TrimPrefix(latest, s.prefix))
+	if err != nil {
+		return "", err
+	}
+
+	return s.prefix + next, nil
+}
+
+// Validate implements TagScheme.
+func (s environmentScheme) Validate(tag string) error {
+	_, err := s.Parse(tag)
+	return err
+}
+
+// PromotedTag returns the tag of the environment which has the same version as the tag of the other environment.
+func (e Environment) PromotedTag(tag string, from Environment) string {
+	return e.Prefix + strings.TrimPrefix(tag, from.Prefix)
+}
+
+// LatestEnvironmentTag picks the newest tag of the environment by the scheme. It returns false when the environment has no tags.
+func LatestEnvironmentTag(scheme TagScheme, env Environment, tags []Tag) (Tag, bool) {
+	byName := map[string]Tag{}
+	names := []string{}
+	for _, t := range tags {
+		if env.Owns(t.Name) {
+			byName[t.Name] = t
+			names = append(names, t.Name)
+		}
+	}
+
+	sorted := SortTags(scheme, names)
+	if len(sorted) == 0 {
+		return Tag{}, false
+	}
+
+	return byName[sorted[0]], true
+}
+
+// PromotedTagOf finds the tag of the environment on the commit. It returns false when the commit is not promoted to the environment.
+func PromotedTagOf(env Environment, commit string, tags []Tag) (Tag, bool) {
+	for _, t := range tags {
+		if t.Commit == commit && env.Owns(t.Name) {
+			return t, true
+		}
+	}
+
+	return Tag{}, false
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fakeEnvironments = map[string]EnvironmentConfig{
+	"staging":    {Prefix: "stg-"},
+	"production": {Prefix: ""},
+}
+
+func TestNewEnvironment(t *testing.T) {
+	env, err := NewEnvironment("production", fakeEnvironments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type pattern struct {
+		exp bool
+		tag string
+	}
+	patterns := []pattern{
+		{true, "20240101.2"},
+		{false, "stg-20240101.2"},
+	}
+
+	for _, p := range patterns {
+		if owns := env.Owns(p.tag); owns != p.exp {
+			t.Errorf("Tag=%q, Output=%t, Expected=%t", p.tag, owns, p.exp)
+		}
+	}
+}
+
+func TestNewEnvironment_Unknown(t *testing.T) {
+	_, err := NewEnvironment("qa", fakeEnvironments)
+
+	expected := `unknown environment "qa"(configured: production, staging)`
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
+
+func TestPromotedTag(t *testing.T) {
+	staging, _ := NewEnvironment("staging", fakeEnvironments)
+	production, _ := NewEnvironment("production", fakeEnvironments)
+
+	if tag := production.PromotedTag("stg-20240101.2", staging); tag != "20240101.2" {
+		t.Errorf("Output=%q, Expected=%q", tag, "20240101.2")
+	}
+	if tag := staging.PromotedTag("20240101.2", production); tag != "stg-20240101.2" {
+		t.Errorf("Output=%q, Expected=%q", tag, "stg-20240101.2")
+	}
+}
+
+func TestLatestEnvironmentTag(t *testing.T) {
+	staging, _ := NewEnvironment("staging", fakeEnvironments)
+	production, _ := NewEnvironment("production", fakeEnvironments)
+	tags := []Tag{
+		{Name: "stg-20240101.10", Commit: "ccc"},
+		{Name: "20240101.1", Commit: "aaa"},
+		{Name: "stg-20240101.2", Commit: "bbb"},
+		{Name: "stg-20240101.1", Commit: "aaa"},
+	}
+
+	if tag, ok := LatestEnvironmentTag(DateVer{}, staging, tags); !ok || tag.Name != "stg-20240101.10" {
+		t.Errorf("Output=%q, Expected=%q", tag.Name, "stg-20240101.10")
+	}
+	if tag, ok := LatestEnvironmentTag(DateVer{}, production, tags); !ok || tag.Name != "20240101.1" {
+		t.Errorf("Output=%q, Expected=%q", tag.Name, "20240101.1")
+	}
+	if _, ok := LatestEnvironmentTag(DateVer{}, production, tags[2:]); ok {
+		t.Errorf("Production tag is found in the staging tags")
+	}
+
+	if tag, ok := PromotedTagOf(production, "aaa", tags); !ok || tag.Name != "20240101.1" {
+		t.Errorf("Output=%q, Expected=%q", tag.Name, "20240101.1")
+	}
+	if _, ok := PromotedTagOf(production, "bbb", tags); ok {
+		t.Errorf("Commit bbb is regarded as promoted")
+	}
+}
+
+func TestEnvironment_Scheme(t *testing.T) {
+	staging, _ := NewEnvironment("staging", fakeEnvironments)
+	production, _ := NewEnvironment("production", fakeEnvironments)
+	scheme := staging.Scheme(SemVer{})
+
+	v, err := scheme.Parse("stg-v1.2.3-rc.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := TagVersion{Tag: "stg-v1.2.3-rc.1", Scheme: "semver", Prefix: "stg-v", Numbers: []int{1, 2, 3}, Prerelease: "rc.1"}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Output=%v, Expected=%v", v, expected)
+	}
+	for _, tag := range []string{"v1.2.3", "stg-1.2"} {
+		if err := scheme.Validate(tag); err == nil {
+			t.Errorf("Expected error, Tag=%q", tag)
+		}
+	}
+	if next, err := scheme.Next("stg-v1.2.3"); err != nil || next != "stg-v1.2.4" {
+		t.Errorf("Output=%q, Expected=%q, Error=%v", next, "stg-v1.2.4", err)
+	}
+
+	// the environment without prefix uses the scheme as it is.
+	if production.Scheme(SemVer{}) != (SemVer{}) {
+		t.Errorf("Output=%v, Expected=%v", production.Scheme(SemVer{}), SemVer{})
+	}
+}
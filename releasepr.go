@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultChangelog is the default file which the release note is prepended to by prepare.
+const DefaultChangelog = "CHANGELOG.md"
+
+// ReleasePRConfig configures the release pull request created by prepare.
+type ReleasePRConfig struct {
+	// Changelog is the file which the release note is prepended to. Empty means DefaultChangelog.
+	Changelog string `json:"changelog"`
+}
+
+// ReleaseBranch returns the branch of the release pull request of the tag.
+func ReleaseBranch(tag string) string {
+	return "release/" + tag
+}
+
+// PullRequest is the pull request in GitHub.
+type PullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	User           GitHubUser `json:"user"`
+	State          string     `json:"state"`
+	HTMLURL        string     `json:"html_url"`
+	MergedAt       *time.Time `json:"merged_at"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+}
+
+// GitHubUser is the user in GitHub.
+type GitHubUser struct {
+	Login string `json:"login"`
+}
+
+// Merged checks the pull request is merged.
+func (p PullRequest) Merged() bool {
+	return p.MergedAt != nil
+}
+
+// Message returns the pull request's title and body in the same format as the release note.
+func (p PullRequest) Message() string {
+	if p.Body == "" {
+		return p.Title
+	}
+
+	return p.Title + "\n\n" + p.Body
+}
+
+// SplitNote splits the release note into the title(the first line) and the body.
+func SplitNote(note string) (string, string) {
+	title, body, _ := strings.Cut(note, "\n")
+	return title, strings.TrimLeft(body, "\n")
+}
+
+// ChangelogChange prepends the body of the release note to the changelog. The file is created if it does not exist.
+// The top heading of the changelog(e.g. "# Changelog") is kept on the top.
+func ChangelogChange(path string, note string) (FileChange, error) {
+	change := FileChange{Path: path}
+	b, err := os.ReadFile(path)
+	if err == nil {
+		info, err := os.Stat(path)
+		if err != nil {
+			return FileChange{}, err
+		}
+		change.Exists, change.Mode = true, info.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return FileChange{}, err
+	}
+	before := string(b)
+
+	_, entry := SplitNote(note)
+	entry = strings.TrimRight(entry, "\n") + "\n"
+
+	after := entry
+	if first, rest, _ := strings.Cut(before, "\n"); strings.HasPrefix(first, "# ") {
+		after = first + "\n\n" + entry
+		if rest = strings.TrimLeft(rest, "\n"); rest != "" {
+			after = after + "\n" + rest
+		}
+	} else if before != "" {
+		after = entry + "\n" + before
+	}
+
+	change.Before, change.After = before, after
+
+	return change, nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestChangelogChange(t *testing.T) {
+	type pattern struct {
+		exp    string
+		before string
+	}
+	patterns := []pattern{
+		{"## v1.2.4\n- itosho: fix bug\n", ""},
+		{"## v1.2.4\n- itosho: fix bug\n\n## v1.2.3\n- kazu: add feature\n", "## v1.2.3\n- kazu: add feature\n"},
+		{"# Changelog\n\n## v1.2.4\n- itosho: fix bug\n\n## v1.2.3\n- kazu: add feature\n", "# Changelog\n\n## v1.2.3\n- kazu: add feature\n"},
+		{"# Changelog\n\n## v1.2.4\n- itosho: fix bug\n", "# Changelog\n"},
+	}
+
+	for _, p := range patterns {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "CHANGELOG.md")
+		if p.before != "" {
+			writeFiles(t, dir, map[string]string{"CHANGELOG.md": p.before})
+		}
+
+		change, err := ChangelogChange(path, "Release v1.2.4\n\n## v1.2.4\n- itosho: fix bug")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if change.Before != p.before || change.After != p.exp {
+			t.Errorf("Output=%q, Expected=%q", change.After, p.exp)
+		}
+		if change.Exists != (p.before != "") {
+			t.Errorf("Exists=%t, Expected=%t", change.Exists, p.before != "")
+		}
+	}
+}
+
+func TestSplitNote(t *testing.T) {
+	title, body := SplitNote("Release v1.2.4\n\n## v1.2.4\n- itosho: fix bug")
+
+	if title != "Release v1.2.4" || body != "## v1.2.4\n- itosho: fix bug" {
+		t.Errorf("Output=%q, %q, Expected=%q, %q", title, body, "Release v1.2.4", "## v1.2.4\n- itosho: fix bug")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Repository is the repository on GitHub which the remote(origin) points to.
+type Repository struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// scpLikeURLRe matches scp-like syntax of git's URL(e.g. git@github.com:Connehito/gdp.git).
+var scpLikeURLRe = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):([^/]+)/(.+?)(?:\.git)?/?$`)
+
+// ParseRemoteURL parses the remote URL(https, ssh or scp-like syntax).
+func ParseRemoteURL(remote string) (Repository, error) {
+	remote = strings.TrimSpace(remote)
+
+	if !strings.Contains(remote, "://") {
+		if m := scpLikeURLRe.FindStringSubmatch(remote); m != nil {
+			return Repository{Host: m[1], Owner: m[2], Name: m[3]}, nil
+		}
+		return Repository{}, fmt.Errorf("unsupported remote url %q", remote)
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	paths := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if u.Hostname() == "" || len(paths) != 2 {
+		return Repository{}, fmt.Errorf("unsupported remote url %q", remote)
+	}
+
+	return Repository{Host: u.Hostname(), Owner: paths[0], Name: strings.TrimSuffix(paths[1], ".git")}, nil
+}
+
+// URL returns the repository's web URL.
+func (r Repository) URL() string {
+	return "https://" + r.Host + "/" + r.Owner + "/" + r.Name
+}
+
+// CompareURL returns the URL comparing from the tag to the tag.
+func (r Repository) CompareURL(fromTag string, toTag string) string {
+	return r.URL() + "/compare/" + fromTag + "..." + toTag
+}
+
+// ReleaseURL returns the URL of the release of the tag.
+func (r Repository) ReleaseURL(tag string) string {
+	return r.URL() + "/releases/tag/" + tag
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseRemoteURL(t *testing.T) {
+	type pattern struct {
+		exp    Repository
+		remote string
+	}
+	expected := Repository{Host: "github.com", Owner: "Connehito", Name: "gdp"}
+	patterns := []pattern{
+		{expected, "git@github.com:Connehito/gdp.git"},
+		{expected, "github.com:Connehito/gdp"},
+		{expected, "https://github.com/Connehito/gdp.git"},
+		{expected, "https://itosho@github.com/Connehito/gdp"},
+		{expected, "ssh://git@github.com/Connehito/gdp.git\n"},
+	}
+
+	for _, p := range patterns {
+		repo, err := ParseRemoteURL(p.remote)
+		if err != nil {
+			t.Errorf("Error=%q, Remote=%q", err.Error(), p.remote)
+		}
+		if repo != p.exp {
+			t.Errorf("Output=%v, Expected=%v, Remote=%q", repo, p.exp, p.remote)
+		}
+	}
+}
+
+func TestParseRemoteURL_Error(t *testing.T) {
+	remotes := []string{"/path/to/gdp.git", "https://github.com/Connehito", ""}
+
+	for _, remote := range remotes {
+		if _, err := ParseRemoteURL(remote); err == nil {
+			t.Errorf("Expected error, Remote=%q", remote)
+		}
+	}
+}
+
+func TestRepository_CompareURL(t *testing.T) {
+	repo := Repository{Host: "github.com", Owner: "Connehito", Name: "gdp"}
+	url := repo.CompareURL("v1.2.3", "v1.2.4")
+
+	expected := "https://github.com/Connehito/gdp/compare/v1.2.3...v1.2.4"
+	if url != expected {
+		t.Errorf("Output=%q, Expected=%q", url, expected)
+	}
+}
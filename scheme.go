@@ -0,0 +1,438 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TagScheme is the definition of the tag's version which creates, compares and validates tags.
+type TagScheme interface {
+	// Name is the name of the scheme selected by the project config.
+	Name() string
+	// Parse parses the tag. It fails when the tag is not the scheme's format.
+	Parse(tag string) (TagVersion, error)
+	// Next gets the next tag of the latest tag. Empty latest tag means the first tag.
+	Next(latest string) (string, error)
+	// Compare returns a negative number when a is older than b, a positive number when a is newer, and 0 when they are same.
+	Compare(a TagVersion, b TagVersion) int
+	// Validate checks the tag is the scheme's format.
+	Validate(tag string) error
+}
+
+// TagVersion is the parsed tag.
+type TagVersion struct {
+	Tag string
+	// Scheme is the name of the scheme which parsed the tag.
+	Scheme string
+	// Prefix is the text before the version(e.g. "v" of v1.2.3 or "api/release-" of api/release-42).
+	Prefix string
+	// Numbers are the numbers of the version in order of significance.
+	Numbers []int
+	// Prerelease is SemVer's pre-release(e.g. "rc.1" of v1.2.3-rc.1).
+	Prerelease string
+}
+
+// SchemeFactory creates the tag scheme from the project config.
+type SchemeFactory func(config Config) (TagScheme, error)
+
+var schemeFactories = map[string]SchemeFactory{
+	"semver": func(Config) (TagScheme, error) { return SemVer{}, nil },
+	"date":   func(Config) (TagScheme, error) { return DateVer{}, nil },
+	"calver": func(config Config) (TagScheme, error) {
+		calver, err := NewCalVer(config.CalVer)
+		if err != nil {
+			return nil, err
+		}
+		return calver, nil
+	},
+	"counter": func(config Config) (TagScheme, error) { return NewCounter(config.Counter), nil },
+}
+
+// NewTagScheme creates the tag scheme selected by the project config.
+// Empty scheme means calver when calver.layout is set, otherwise semver or date by the latest tag's format.
+func NewTagScheme(config Config) (TagScheme, error) {
+	name := config.Scheme
+	if name == "" && config.CalVer.Layout != "" {
+		name = "calver"
+	}
+	if name == "" {
+		return autoScheme{}, nil
+	}
+
+	factory, ok := schemeFactories[name]
+	if !ok {
+		names := []string{}
+		for n := range schemeFactories {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown tag scheme %q(supported: %s)", name, strings.Join(names, ", "))
+	}
+
+	return factory(config)
+}
+
+// autoScheme is semver or date by the tag's format, which is gdp's default.
+type autoScheme struct{}
+
+// Name implements TagScheme.
+func (autoScheme) Name() string {
+	return "auto"
+}
+
+// Parse implements TagScheme.
+func (autoScheme) Parse(tag string) (TagVersion, error) {
+	if v, err := (SemVer{}).Parse(tag); err == nil {
+		return v, nil
+	}
+	if v, err := (DateVer{}).Parse(tag); err == nil {
+		return v, nil
+	}
+
+	return TagVersion{}, fmt.Errorf("tag %q is neither semantic(e.g. v1.2.3) nor date(e.g. 20180525.1) format", tag)
+}
+
+// Next implements TagScheme.
+func (s autoScheme) Next(latest string) (string, error) {
+	if latest == "" {
+		return (SemVer{}).Next(latest)
+	}
+
+	v, err := s.Parse(latest)
+	if err != nil {
+		return "", fmt.Errorf("latest %s", err.Error())
+	}
+	if v.Scheme == "date" {
+		return (DateVer{}).Next(latest)
+	}
+
+	return (SemVer{}).Next(latest)
+}
+
+// Compare implements TagScheme. The tags of different schemes are not compared, and they are same.
+func (autoScheme) Compare(a TagVersion, b TagVersion) int {
+	if a.Scheme != b.Scheme {
+		return 0
+	}
+	if a.Scheme == "date" {
+		return (DateVer{}).Compare(a, b)
+	}
+
+	return (SemVer{}).Compare(a, b)
+}
+
+// Validate implements TagScheme.
+func (s autoScheme) Validate(tag string) error {
+	_, err := s.Parse(tag)
+	return err
+}
+
+// SemVer is the semantic versioning(https://semver.org/) e.g. v1.2.3 or api/v1.2.3-rc.1.
+type SemVer struct{}
+
+var semVerRe = regexp.MustCompile(`^((?:.*/)?v?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// Name implements TagScheme.
+func (SemVer) Name() string {
+	return "semver"
+}
+
+// Parse implements TagScheme.
+func (s SemVer) Parse(tag string) (TagVersion, error) {
+	m := semVerRe.FindStringSubmatch(tag)
+	if m == nil {
+		return TagVersion{}, fmt.Errorf("tag %q is not semantic version(e.g. v1.2.3)", tag)
+	}
+
+	v := TagVersion{Tag: tag, Scheme: s.Name(), Prefix: m[1], Prerelease: m[5]}
+	for _, n := range m[2:5] {
+		i, err := strconv.Atoi(n)
+		if err != nil {
+			return TagVersion{}, err
+		}
+		v.Numbers = append(v.Numbers, i)
+	}
+
+	return v, nil
+}
+
+// Next implements TagScheme. The last number of pre-release is incremented(e.g. v1.2.3-rc.2 of v1.2.3-rc.1),
+// and the other pre-release is released(e.g. v1.2.3 of v1.2.3-beta). Otherwise the patch is incremented.
+func (s SemVer) Next(latest string) (string, error) {
+	if latest == "" {
+		return "v1.0.0", nil
+	}
+
+	v, err := s.Parse(latest)
+	if err != nil {
+		return "", fmt.Errorf("latest %s", err.Error())
+	}
+	version := fmt.Sprintf("%s%d.%d.", v.Prefix, v.Numbers[0], v.Numbers[1])
+
+	if v.Prerelease != "" {
+		ids := strings.Split(v.Prerelease, ".")
+		n, err := strconv.Atoi(ids[len(ids)-1])
+		if err != nil {
+			return version + strconv.Itoa(v.Numbers[2]), nil
+		}
+		ids[len(ids)-1] = strconv.Itoa(n + 1)
+		return version + strconv.Itoa(v.Numbers[2]) + "-" + strings.Join(ids, "."), nil
+	}
+
+	return version + strconv.Itoa(v.Numbers[2]+1), nil
+}
+
+// Compare implements TagScheme. The pre-release is older than the release.
+func (SemVer) Compare(a TagVersion, b TagVersion) int {
+	if c := compareNumbers(a.Numbers, b.Numbers); c != 0 {
+		return c
+	}
+
+	switch {
+	case a.Prerelease == b.Prerelease:
+		return 0
+	case a.Prerelease == "":
+		return 1
+	case b.Prerelease == "":
+		return -1
+	}
+
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// Validate implements TagScheme.
+func (s SemVer) Validate(tag string) error {
+	_, err := s.Parse(tag)
+	return err
+}
+
+// comparePrerelease compares pre-releases by the identifiers(e.g. alpha.1 < alpha.beta < beta.2 < beta.11).
+func comparePrerelease(a string, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return compareInts(an, bn)
+			}
+		case aErr == nil:
+			return -1 // numeric identifier is lower
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return compareInts(len(as), len(bs))
+}
+
+// DateVer is the date version e.g. 20180525.1 or release_20180525.
+type DateVer struct{}
+
+var dateVerRe = regexp.MustCompile(`^((?:.*\D)?)(\d{8})(?:\.(\d+))?$`)
+
+// Name implements TagScheme.
+func (DateVer) Name() string {
+	return "date"
+}
+
+// Parse implements TagScheme. The tag without the number of the date is the number 0.
+func (s DateVer) Parse(tag string) (TagVersion, error) {
+	m := dateVerRe.FindStringSubmatch(tag)
+	if m == nil {
+		return TagVersion{}, fmt.Errorf("tag %q is not date version(e.g. 20180525.1)", tag)
+	}
+
+	date, err := strconv.Atoi(m[2])
+	if err != nil {
+		return TagVersion{}, err
+	}
+	number := 0
+	if m[3] != "" {
+		if number, err = strconv.Atoi(m[3]); err != nil {
+			return TagVersion{}, err
+		}
+	}
+
+	return TagVersion{Tag: tag, Scheme: s.Name(), Prefix: m[1], Numbers: []int{date, number}}, nil
+}
+
+// Next implements TagScheme. The number is incremented within the current date.
+func (s DateVer) Next(latest string) (string, error) {
+	const layout = "20060102"
+	today := now().Format(layout)
+	if latest == "" {
+		return today + ".1", nil
+	}
+
+	v, err := s.Parse(latest)
+	if err != nil {
+		return "", fmt.Errorf("latest %s", err.Error())
+	}
+	if strconv.Itoa(v.Numbers[0]) == today && v.Numbers[1] > 0 {
+		return v.Prefix + today + "." + strconv.Itoa(v.Numbers[1]+1), nil
+	}
+
+	return v.Prefix + today + ".1", nil
+}
+
+// Compare implements TagScheme.
+func (DateVer) Compare(a TagVersion, b TagVersion) int {
+	return compareNumbers(a.Numbers, b.Numbers)
+}
+
+// Validate implements TagScheme.
+func (s DateVer) Validate(tag string) error {
+	_, err := s.Parse(tag)
+	return err
+}
+
+// CounterConfig configures the counter scheme.
+type CounterConfig struct {
+	// Prefix is the text before the counter. Empty means "release-".
+	Prefix string `json:"prefix"`
+}
+
+// Counter is the scheme which counts up the number e.g. release-42.
+type Counter struct {
+	prefix string
+}
+
+// NewCounter creates the counter scheme from the config.
+func NewCounter(config CounterConfig) Counter {
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = "release-"
+	}
+
+	return Counter{prefix: prefix}
+}
+
+var counterRe = regexp.MustCompile(`^((?:.*\D)?)(\d+)$`)
+
+// Name implements TagScheme.
+func (Counter) Name() string {
+	return "counter"
+}
+
+// Parse implements TagScheme. The tag can have the component's prefix before the counter's prefix(e.g. api/release-42).
+func (s Counter) Parse(tag string) (TagVersion, error) {
+	m := counterRe.FindStringSubmatch(tag)
+	if m == nil || !strings.HasSuffix(m[1], s.prefix) {
+		return TagVersion{}, fmt.Errorf("tag %q is not counter(e.g. %s42)", tag, s.prefix)
+	}
+
+	n, err := strconv.Atoi(m[2])
+	if err != nil {
+		return TagVersion{}, err
+	}
+
+	return TagVersion{Tag: tag, Scheme: s.Name(), Prefix: m[1], Numbers: []int{n}}, nil
+}
+
+// Next implements TagScheme.
+func (s Counter) Next(latest string) (string, error) {
+	if latest == "" {
+		return s.prefix + "1", nil
+	}
+
+	v, err := s.Parse(latest)
+	if err != nil {
+		return "", fmt.Errorf("latest %s", err.Error())
+	}
+
+	return v.Prefix + strconv.Itoa(v.Numbers[0]+1), nil
+}
+
+// Compare implements TagScheme.
+func (Counter) Compare(a TagVersion, b TagVersion) int {
+	return compareNumbers(a.Numbers, b.Numbers)
+}
+
+// Validate implements TagScheme.
+func (s Counter) Validate(tag string) error {
+	_, err := s.Parse(tag)
+	return err
+}
+
+// SortTags sorts the tags by the scheme in order of newest first. The tags which are not the scheme's format are removed.
+// The tags parsed by the other scheme than the first tag's(e.g. date tags before the migration to semver) are removed too,
+// because they can not be compared. The tags are listed in order of newest created first, so the first tag's scheme is the current one.
+func SortTags(scheme TagScheme, tags []string) []string {
+	versions := []TagVersion{}
+	for _, tag := range tags {
+		if v, err := scheme.Parse(tag); err == nil && (len(versions) == 0 || v.Scheme == versions[0].Scheme) {
+			versions = append(versions, v)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return scheme.Compare(versions[i], versions[j]) > 0
+	})
+
+	sorted := []string{}
+	for _, v := range versions {
+		sorted = append(sorted, v.Tag)
+	}
+
+	return sorted
+}
+
+// IsComparableTag checks the tag can be compared with the latest tag by the scheme, which means they are the scheme's format
+// and have the same prefix and scheme. It's true when there is no latest tag.
+func IsComparableTag(scheme TagScheme, tag string, latestTag string) bool {
+	if latestTag == "" {
+		return true
+	}
+	if scheme.Validate(tag) != nil || scheme.Validate(latestTag) != nil {
+		return false
+	}
+
+	return TagFamily(scheme, tag) == TagFamily(scheme, latestTag)
+}
+
+// IsNewerTag checks the tag is newer than the latest tag by the scheme. It's true when there is no latest tag,
+// and false when they can not be compared(see IsComparableTag).
+func IsNewerTag(scheme TagScheme, tag string, latestTag string) bool {
+	if latestTag == "" {
+		return true
+	}
+	v, err := scheme.Parse(tag)
+	if err != nil {
+		return false
+	}
+	latest, err := scheme.Parse(latestTag)
+	if err != nil || TagFamily(scheme, tag) != TagFamily(scheme, latestTag) {
+		return false
+	}
+
+	return scheme.Compare(v, latest) > 0
+}
+
+func compareNumbers(a []int, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return compareInts(a[i], b[i])
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+func compareInts(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTagScheme(t *testing.T) {
+	type pattern struct {
+		exp    string
+		config Config
+	}
+	patterns := []pattern{
+		{"auto", Config{}},
+		{"semver", Config{Scheme: "semver"}},
+		{"date", Config{Scheme: "date"}},
+		{"calver", Config{CalVer: CalVerConfig{Layout: "YYYY.0M.0D"}}},
+		{"counter", Config{Scheme: "counter"}},
+	}
+
+	for _, p := range patterns {
+		scheme, err := NewTagScheme(p.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scheme.Name() != p.exp {
+			t.Errorf("Output=%q, Expected=%q", scheme.Name(), p.exp)
+		}
+	}
+}
+
+func TestNewTagScheme_Unknown(t *testing.T) {
+	_, err := NewTagScheme(Config{Scheme: "roman"})
+
+	expected := `unknown tag scheme "roman"(supported: calver, counter, date, semver)`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+}
+
+func TestSemVer_Parse(t *testing.T) {
+	v, err := SemVer{}.Parse("api/v1.2.3-rc.1+build.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := TagVersion{Tag: "api/v1.2.3-rc.1+build.5", Scheme: "semver", Prefix: "api/v", Numbers: []int{1, 2, 3}, Prerelease: "rc.1"}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Output=%v, Expected=%v", v, expected)
+	}
+
+	for _, tag := range []string{"v1.2", "release-1.2.3", "20180525.1"} {
+		if err := (SemVer{}).Validate(tag); err == nil {
+			t.Errorf("Expected error, Tag=%q", tag)
+		}
+	}
+}
+
+func TestSemVer_Next(t *testing.T) {
+	type pattern struct {
+		exp string
+		tag string
+	}
+	patterns := []pattern{
+		{"v1.0.0", ""},
+		{"v1.2.4", "v1.2.3"},
+		{"api/1.2.4", "api/1.2.3"},
+		{"v1.2.3-rc.2", "v1.2.3-rc.1"},
+		{"v1.2.3", "v1.2.3-beta"},
+	}
+
+	for _, p := range patterns {
+		next, err := SemVer{}.Next(p.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Tag=%q", next, p.exp, p.tag)
+		}
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	type pattern struct {
+		exp  int
+		a, b string
+	}
+	patterns := []pattern{
+		{-1, "v1.2.3", "v1.2.4"},
+		{1, "v1.10.0", "v1.9.9"},
+		{0, "v1.2.3", "1.2.3"},
+		{-1, "v1.2.3-rc.1", "v1.2.3"},
+		{-1, "v1.2.3-alpha.1", "v1.2.3-alpha.beta"},
+		{-1, "v1.2.3-beta.2", "v1.2.3-beta.11"},
+		{1, "v1.2.3-rc.1.1", "v1.2.3-rc.1"},
+	}
+
+	s := SemVer{}
+	for _, p := range patterns {
+		a, _ := s.Parse(p.a)
+		b, _ := s.Parse(p.b)
+		if c := s.Compare(a, b); c != p.exp {
+			t.Errorf("Output=%d, Expected=%d, A=%q, B=%q", c, p.exp, p.a, p.b)
+		}
+	}
+}
+
+func TestDateVer(t *testing.T) {
+	fakeNow(t, time.Date(2020, 4, 1, 17, 00, 00, 0, time.Local))
+
+	s := DateVer{}
+	v, err := s.Parse("release_20200401")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := TagVersion{Tag: "release_20200401", Scheme: "date", Prefix: "release_", Numbers: []int{20200401, 0}}
+	if !reflect.DeepEqual(v, expected) {
+		t.Errorf("Output=%v, Expected=%v", v, expected)
+	}
+
+	older, _ := s.Parse("20200331.9")
+	newer, _ := s.Parse("20200401.2")
+	if s.Compare(older, newer) >= 0 {
+		t.Errorf("Expected %q < %q", older.Tag, newer.Tag)
+	}
+
+	next, _ := s.Next("20200401.2")
+	if next != "20200401.3" {
+		t.Errorf("Output=%q, Expected=%q", next, "20200401.3")
+	}
+}
+
+func TestCounter(t *testing.T) {
+	s := NewCounter(CounterConfig{})
+
+	type pattern struct {
+		exp string
+		tag string
+	}
+	patterns := []pattern{
+		{"release-1", ""},
+		{"release-42", "release-41"},
+		{"api/release-10", "api/release-9"},
+	}
+	for _, p := range patterns {
+		next, err := s.Next(p.tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Tag=%q", next, p.exp, p.tag)
+		}
+	}
+
+	_, err := s.Next("v1.2.3")
+	expected := `latest tag "v1.2.3" is not counter(e.g. release-42)`
+	if err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Output=%v, Expected=%q", err, expected)
+	}
+
+	a, _ := s.Parse("release-9")
+	b, _ := s.Parse("release-10")
+	if s.Compare(a, b) >= 0 {
+		t.Errorf("Expected %q < %q", a.Tag, b.Tag)
+	}
+}
+
+func TestIsComparableTag(t *testing.T) {
+	type pattern struct {
+		exp         bool
+		tag, latest string
+	}
+	patterns := []pattern{
+		{true, "v1.2.4", "v1.2.3"},
+		{true, "v1.2.2", "v1.2.3"},
+		{true, "v1.0.0", ""},
+		{false, "api/v1.0.0", "web/v1.2.3"},
+		{false, "1.0.0", "v1.2.3"},
+		{false, "20200101.1", "v1.2.3"},
+		{false, "v1.2", "v1.2.3"},
+	}
+
+	for _, p := range patterns {
+		if IsComparableTag(autoScheme{}, p.tag, p.latest) != p.exp {
+			t.Errorf("Output=%t, Expected=%t, Tag=%q, Latest=%q", !p.exp, p.exp, p.tag, p.latest)
+		}
+	}
+}
+
+func TestIsNewerTag(t *testing.T) {
+	type pattern struct {
+		exp         bool
+		tag, latest string
+	}
+	patterns := []pattern{
+		{true, "v1.2.4", "v1.2.3"},
+		{false, "v1.2.3", "v1.2.3"},
+		{false, "v1.2.3-rc.1", "v1.2.3"},
+		{false, "release_20180524.1", "release_20180525.1"},
+		{true, "v1.0.0", ""},
+		{false, "api/v1.0.0", "web/v1.2.3"},
+		{false, "1.0.0", "v1.2.3"},
+		{false, "20200101.1", "v1.2.3"},
+	}
+
+	for _, p := range patterns {
+		if IsNewerTag(autoScheme{}, p.tag, p.latest) != p.exp {
+			t.Errorf("Output=%t, Expected=%t, Tag=%q, Latest=%q", !p.exp, p.exp, p.tag, p.latest)
+		}
+	}
+}
+
+func TestSortTags(t *testing.T) {
+	tags := []string{"v1.9.0", "latest", "v1.10.0", "v1.10.0-rc.1", "v1.2.3"}
+	sorted := SortTags(SemVer{}, tags)
+
+	expected := []string{"v1.10.0", "v1.10.0-rc.1", "v1.9.0", "v1.2.3"}
+	if !reflect.DeepEqual(sorted, expected) {
+		t.Errorf("Output=%q, Expected=%q", sorted, expected)
+	}
+}
+
+func TestSortTags_AutoScheme(t *testing.T) {
+	type pattern struct {
+		exp  []string
+		tags []string
+	}
+	patterns := []pattern{
+		// the tags of the other scheme than the newest created tag's are removed.
+		{[]string{"v1.0.0", "v0.9.0"}, []string{"v0.9.0", "20240101.1", "v1.0.0", "20231231.3"}},
+		{[]string{"20240101.1", "20231231.3"}, []string{"20231231.3", "v2.0.0", "20240101.1"}},
+	}
+
+	for _, p := range patterns {
+		if sorted := SortTags(autoScheme{}, p.tags); !reflect.DeepEqual(sorted, p.exp) {
+			t.Errorf("Output=%q, Expected=%q", sorted, p.exp)
+		}
+	}
+}
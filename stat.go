@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// RangeStat is the statistics of commits range from previous tag to the tag.
+type RangeStat struct {
+	// FromTag is the previous tag. Empty means the range starts from the root commit.
+	FromTag      string
+	Commits      int
+	FilesChanged int
+	Insertions   int
+	Deletions    int
+}
+
+var shortStatRe = regexp.MustCompile(`(\d+) (file|insertion|deletion)`)
+
+// parseShortStat parses git diff --shortstat's output(e.g. 3 files changed, 10 insertions(+), 2 deletions(-)).
+func parseShortStat(out string, stat *RangeStat) {
+	for _, m := range shortStatRe.FindAllStringSubmatch(out, -1) {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "file":
+			stat.FilesChanged = n
+		case "insertion":
+			stat.Insertions = n
+		case "deletion":
+			stat.Deletions = n
+		}
+	}
+}
+
+// String formats the statistics for the release note.
+func (s RangeStat) String() string {
+	stats := []string{plural(s.Commits, "commit")}
+	if s.FromTag != "" {
+		stats = append(stats,
+			plural(s.FilesChanged, "file")+" changed",
+			plural(s.Insertions, "insertion")+"(+)",
+			plural(s.Deletions, "deletion")+"(-)",
+		)
+	}
+
+	return strings.Join(stats, ", ")
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+
+	return fmt.Sprintf("%d %ss", n, word)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseShortStat(t *testing.T) {
+	type pattern struct {
+		exp RangeStat
+		out string
+	}
+	patterns := []pattern{
+		{RangeStat{FilesChanged: 3, Insertions: 10, Deletions: 2}, " 3 files changed, 10 insertions(+), 2 deletions(-)\n"},
+		{RangeStat{FilesChanged: 1, Insertions: 1}, " 1 file changed, 1 insertion(+)\n"},
+		{RangeStat{FilesChanged: 1, Deletions: 5}, " 1 file changed, 5 deletions(-)\n"},
+		{RangeStat{}, ""},
+	}
+
+	for _, p := range patterns {
+		stat := RangeStat{}
+		parseShortStat(p.out, &stat)
+		if stat != p.exp {
+			t.Errorf("Output=%v, Expected=%v, Stat=%q", stat, p.exp, p.out)
+		}
+	}
+}
+
+func TestRangeStat_String(t *testing.T) {
+	type pattern struct {
+		exp  string
+		stat RangeStat
+	}
+	patterns := []pattern{
+		{"12 commits, 3 files changed, 10 insertions(+), 1 deletion(-)", RangeStat{FromTag: "v1.2.3", Commits: 12, FilesChanged: 3, Insertions: 10, Deletions: 1}},
+		{"1 commit", RangeStat{Commits: 1}},
+	}
+
+	for _, p := range patterns {
+		if p.stat.String() != p.exp {
+			t.Errorf("Output=%q, Expected=%q", p.stat.String(), p.exp)
+		}
+	}
+}
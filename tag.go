@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// Tag is the tag in the repository.
+type Tag struct {
+	Name   string    `json:"name"`
+	Date   time.Time `json:"date"`
+	Tagger string    `json:"tagger"`
+	Commit string    `json:"commit"`
+}
+
+// tagRefFormat is git for-each-ref's format which parseTagRefs can parse.
+// The tagger of lightweight tag is the author of the commit.
+const tagRefFormat = "--format=%(refname:short)%1f%(creatordate:iso-strict)%1f%(taggername)%1f%(authorname)%1f%(*objectname)%1f%(objectname)"
+
+// parseTagRefs parses git for-each-ref's output formatted with tagRefFormat.
+func parseTagRefs(out string) []Tag {
+	tags := []Tag{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, fieldSeparator)
+		if len(fields) != 6 {
+			continue
+		}
+
+		tag := Tag{Name: fields[0], Tagger: fields[2], Commit: fields[4]}
+		tag.Date, _ = time.Parse(time.RFC3339, fields[1])
+		if tag.Tagger == "" {
+			tag.Tagger = fields[3]
+		}
+		if tag.Commit == "" {
+			tag.Commit = fields[5] // lightweight tag
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags
+}
+
+// TagFamily returns the scheme and prefix of the tag(e.g. "semver:v" for v1.2.3, "semver:api/v" for api/v1.2.3,
+// "date:release_" for release_20180525). Empty means the tag is not the scheme's format.
+func TagFamily(scheme TagScheme, tag string) string {
+	v, err := scheme.Parse(tag)
+	if err != nil {
+		return ""
+	}
+
+	return v.Scheme + ":" + v.Prefix
+}
+
+// previousTag picks the first tag of the candidates which has the same family as the tag.
+// All candidates are considered when the tag is not the scheme's format.
+func previousTag(scheme TagScheme, tag string, candidates []string) string {
+	family := TagFamily(scheme, tag)
+	for _, c := range candidates {
+		if c == "" || c == tag {
+			continue
+		}
+		if family == "" || TagFamily(scheme, c) == family {
+			return c
+		}
+	}
+
+	return ""
+}
+
+// ReleaseHistory is the tag released by gdp.
+type ReleaseHistory struct {
+	Tag
+	// PullRequests is the number of pull requests of the commits since previous tag, which are selected by the strategy.
+	PullRequests int  `json:"pull_requests"`
+	Published    bool `json:"published"`
+	// Environments are the environments which the tag is deployed to currently.
+	Environments []string `json:"environments,omitempty"`
+}
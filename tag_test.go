@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTagRefs(t *testing.T) {
+	out := "v1.0.1\x1f2020-04-02T10:00:00+09:00\x1fitosho\x1f\x1fc0ffee\x1ftag0001\n"
+	out = out + "v1.0.0\x1f2020-04-01T10:00:00+09:00\x1f\x1fkazu\x1f\x1fdecade\n"
+	tags := parseTagRefs(out)
+
+	jst := time.FixedZone("", 9*60*60)
+	expected := []Tag{
+		{Name: "v1.0.1", Date: time.Date(2020, 4, 2, 10, 0, 0, 0, jst), Tagger: "itosho", Commit: "c0ffee"},
+		{Name: "v1.0.0", Date: time.Date(2020, 4, 1, 10, 0, 0, 0, jst), Tagger: "kazu", Commit: "decade"},
+	}
+	if len(tags) != len(expected) {
+		t.Fatalf("Output=%v, Expected=%v", tags, expected)
+	}
+	for i := range tags {
+		if !tags[i].Date.Equal(expected[i].Date) {
+			t.Errorf("Output=%v, Expected=%v", tags[i].Date, expected[i].Date)
+		}
+		tags[i].Date, expected[i].Date = time.Time{}, time.Time{}
+	}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("Output=%v, Expected=%v", tags, expected)
+	}
+}
+
+func TestTagFamily(t *testing.T) {
+	type pattern struct {
+		exp string
+		tag string
+	}
+	patterns := []pattern{
+		{"semver:v", "v1.2.3"},
+		{"semver:v", "v1.2.3-rc.1"},
+		{"semver:", "1.2.3"},
+		{"date:", "20180525.1"},
+		{"date:release_", "release_20180525"},
+		{"date:hotfix_", "hotfix_20180525.2"},
+		{"semver:api/v", "api/v1.2.3"},
+		{"date:web/release_", "web/release_20180525"},
+		{"", "latest"},
+	}
+
+	for _, p := range patterns {
+		if TagFamily(autoScheme{}, p.tag) != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Tag=%q", TagFamily(autoScheme{}, p.tag), p.exp, p.tag)
+		}
+	}
+}
+
+func TestPreviousTag(t *testing.T) {
+	type pattern struct {
+		exp string
+		tag string
+	}
+	candidates := []string{"hotfix_20180601", "v1.3.0", "release_20180525", "1.2.0", "v1.2.3"}
+	patterns := []pattern{
+		{"v1.3.0", "v1.3.1"},
+		{"v1.2.3", "v1.3.0"},
+		{"1.2.0", "1.2.1"},
+		{"release_20180525", "release_20180602"},
+		{"hotfix_20180601", "latest"},
+		{"", "api/v1.2.4"},
+		{"", "20180602.1"},
+	}
+
+	for _, p := range patterns {
+		if previousTag(autoScheme{}, p.tag, candidates) != p.exp {
+			t.Errorf("Output=%q, Expected=%q, Tag=%q", previousTag(autoScheme{}, p.tag, candidates), p.exp, p.tag)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// DefaultVersionCommitMessage is the default message of the commit updating the version files.
+const DefaultVersionCommitMessage = "Bump version to {tag}"
+
+// VersionFilesConfig configures the files embedding the version which are updated on deploy.
+type VersionFilesConfig struct {
+	Files []VersionFileConfig `json:"files"`
+	// Message is the commit message. {tag} and {version} are replaced. Empty means DefaultVersionCommitMessage.
+	Message string `json:"message"`
+}
+
+// VersionFileConfig configures the file embedding the version.
+// The whole content is the version when none of Pattern, JSON and YAML is set.
+type VersionFileConfig struct {
+	Path string `json:"path"`
+	// Pattern is the regular expression whose first group is the version(e.g. `Version = "(.*)"`).
+	Pattern string `json:"pattern"`
+	// JSON is the dot-separated key path of the version in the JSON file(e.g. version).
+	JSON string `json:"json"`
+	// YAML is the dot-separated key path of the version in the YAML file(e.g. appVersion).
+	YAML string `json:"yaml"`
+	// Format is the value written to the file. {tag} and {version} are replaced. Empty means {version}.
+	Format string `json:"format"`
+}
+
+// VersionFile updates the version embedded in the file.
+type VersionFile struct {
+	Path   string
+	format string
+	update func(content string, value string) (string, error)
+}
+
+// NewVersionFiles creates the version files from the configs.
+func NewVersionFiles(configs []VersionFileConfig) ([]VersionFile, error) {
+	files := []VersionFile{}
+	for _, c := range configs {
+		if c.Path == "" {
+			return nil, errors.New("version file path is empty")
+		}
+
+		kinds := 0
+		for _, s := range []string{c.Pattern, c.JSON, c.YAML} {
+			if s != "" {
+				kinds++
+			}
+		}
+		if kinds > 1 {
+			return nil, fmt.Errorf("version file %s must have only one of pattern, json and yaml", c.Path)
+		}
+
+		f := VersionFile{Path: c.Path, format: c.Format}
+		if f.format == "" {
+			f.format = "{version}"
+		}
+
+		switch {
+		case c.Pattern != "":
+			re, err := regexp.Compile(c.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of version file %s: %w", c.Path, err)
+			}
+			if re.NumSubexp() < 1 {
+				return nil, fmt.Errorf("pattern of version file %s has no group of the version", c.Path)
+			}
+			f.update = func(content string, value string) (string, error) {
+				return replacePattern(content, re, value)
+			}
+		case c.JSON != "":
+			keys := strings.Split(c.JSON, ".")
+			f.update = func(content string, value string) (string, error) {
+				return replaceJSONValue(content, keys, value)
+			}
+		case c.YAML != "":
+			keys := strings.Split(c.YAML, ".")
+			f.update = func(content string, value string) (string, error) {
+				return replaceYAMLValue(content, keys, value)
+			}
+		default:
+			f.update = replaceContent
+		}
+
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// Update returns the content having the tag's version.
+func (f VersionFile) Update(content string, tag string, version string) (string, error) {
+	value := strings.NewReplacer("{tag}", tag, "{version}", version).Replace(f.format)
+	updated, err := f.update(content, value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", f.Path, err)
+	}
+
+	return updated, nil
+}
+
+// FileChange is the change of the file's content.
+type FileChange struct {
+	Path   string
+	Before string
+	After  string
+	// Exists is whether the file exists before the change, and Mode is its permission then.
+	Exists bool
+	Mode   fs.FileMode
+}
+
+// Diff returns the changed lines of the file with a line of context around them.
+func (c FileChange) Diff() string {
+	before, after := strings.TrimRight(c.Before, "\n"), strings.TrimRight(c.After, "\n")
+	lines := []string{}
+	if before == "" {
+		// all lines are added to the new file.
+		for _, line := range strings.Split(after, "\n") {
+			lines = append(lines, "+ "+line)
+		}
+	} else {
+		lines = strings.Split(DiffLines(before, after), "\n")
+	}
+	changed := func(i int) bool {
+		return i >= 0 && i < len(lines) && !strings.HasPrefix(lines[i], "  ")
+	}
+
+	diff := []string{"--- " + c.Path, "+++ " + c.Path}
+	skipped := false
+	for i, line := range lines {
+		if !changed(i-1) && !changed(i) && !changed(i+1) {
+			skipped = true
+			continue
+		}
+		if skipped && len(diff) > 2 {
+			diff = append(diff, "  ...")
+		}
+		skipped = false
+		diff = append(diff, line)
+	}
+
+	return strings.Join(diff, "\n")
+}
+
+// VersionFileChanges reads the version files and returns the changes to the tag's version.
+// The files already having the version are not changed.
+func VersionFileChanges(files []VersionFile, tag string, version string) ([]FileChange, error) {
+	changes := []FileChange{}
+	for _, f := range files {
+		b, err := os.ReadFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(f.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := f.Update(string(b), tag, version)
+		if err != nil {
+			return nil, err
+		}
+		if after != string(b) {
+			changes = append(changes, FileChange{Path: f.Path, Before: string(b), After: after, Exists: true, Mode: info.Mode().Perm()})
+		}
+	}
+
+	return changes, nil
+}
+
+// WriteFileChanges writes the changed contents to the files. The files which do not exist are created.
+func WriteFileChanges(changes []FileChange) error {
+	for _, c := range changes {
+		perm := fs.FileMode(0o644)
+		info, err := os.Stat(c.Path)
+		if err == nil {
+			perm = info.Mode().Perm()
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		if err := os.WriteFile(c.Path, []byte(c.After), perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RevertFileChanges writes back the contents and the permissions before the changes. The files created by the changes are removed.
+func RevertFileChanges(changes []FileChange) error {
+	for _, c := range changes {
+		if !c.Exists {
+			if err := os.Remove(c.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(c.Path, []byte(c.Before), c.Mode); err != nil {
+			return err
+		}
+		if err := os.Chmod(c.Path, c.Mode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VersionOf returns the version of the tag without its prefix(e.g. 1.2.3 for api/v1.2.3).
+func VersionOf(scheme TagScheme, tag string) string {
+	if v, err := scheme.Parse(tag); err == nil {
+		return strings.TrimPrefix(tag, v.Prefix)
+	}
+
+	version := tag[strings.LastIndex(tag, "/")+1:]
+	return strings.TrimPrefix(version, "v")
+}
+
+// replaceContent replaces the whole content keeping the trailing line break.
+func replaceContent(content string, value string) (string, error) {
+	if content == "" || strings.HasSuffix(content, "\n") {
+		return value + "\n", nil
+	}
+
+	return value, nil
+}
+
+// replacePattern replaces the first group of all matches of the pattern.
+func replacePattern(content string, re *regexp.Regexp, value string) (string, error) {
+	matches := re.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("pattern %q is not found", re.String())
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		if m[2] < 0 {
+			continue
+		}
+		b.WriteString(content[last:m[2]])
+		b.WriteString(value)
+		last = m[3]
+	}
+	b.WriteString(content[last:])
+
+	return b.String(), nil
+}
+
+// replaceJSONValue replaces the value of the key path keeping the format of the JSON.
+func replaceJSONValue(content string, keys []string, value string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(content))
+	start, end, err := findJSONValue(dec, keys)
+	if errors.Is(err, errKeyNotFound) || errors.Is(err, errNotScalar) {
+		return "", fmt.Errorf("key %q is %w", strings.Join(keys, "."), err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// the offset after the key is before the colon.
+	start += int64(strings.IndexFunc(content[start:end], func(r rune) bool {
+		return !strings.ContainsRune(": \t\r\n", r)
+	}))
+	quoted, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return content[:start] + string(quoted) + content[end:], nil
+}
+
+var (
+	errKeyNotFound = errors.New("not found")
+	errNotScalar   = errors.New("not a scalar")
+)
+
+// findJSONValue finds the offsets of the scalar value of the key path in the object which the decoder reads next.
+// The start offset is the one after the key.
+func findJSONValue(dec *json.Decoder, keys []string) (int64, int64, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return 0, 0, err
+	}
+	if t != json.Delim('{') {
+		return 0, 0, errKeyNotFound
+	}
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		if t != keys[0] {
+			if err := skipJSONValue(dec); err != nil {
+				return 0, 0, err
+			}
+			continue
+		}
+		if len(keys) > 1 {
+			return findJSONValue(dec, keys[1:])
+		}
+
+		start := dec.InputOffset()
+		t, err = dec.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		if _, ok := t.(json.Delim); ok {
+			return 0, 0, errNotScalar
+		}
+		return start, dec.InputOffset(), nil
+	}
+
+	return 0, 0, errKeyNotFound
+}
+
+// skipJSONValue reads the value including the nested objects and arrays.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// yamlKeyRe matches the line of the mapping's key. The value starts at the end of the match.
+var yamlKeyRe = regexp.MustCompile(`^([ ]*)("[^"]*"|'[^']*'|[^\s#:'"][^\s:]*):(?:[ \t]+|$)`)
+
+// replaceYAMLValue replaces the scalar value of the key path line by line keeping the comments and the quotes.
+// It supports the block mappings which are enough for the files like Helm's Chart.yaml.
+func replaceYAMLValue(content string, keys []string, value string) (string, error) {
+	type key struct {
+		indent int
+		name   string
+	}
+
+	lines := strings.Split(content, "\n")
+	path := []key{}
+	for i, line := range lines {
+		m := yamlKeyRe.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+
+		indent := m[3] - m[2]
+		for len(path) > 0 && path[len(path)-1].indent >= indent {
+			path = path[:len(path)-1]
+		}
+		path = append(path, key{indent: indent, name: strings.Trim(line[m[4]:m[5]], `"'`)})
+		if len(path) != len(keys) {
+			continue
+		}
+		matched := true
+		for j, k := range path {
+			matched = matched && k.name == keys[j]
+		}
+		if !matched {
+			continue
+		}
+
+		rest := line[m[1]:]
+		comment := ""
+		if c := strings.Index(rest, " #"); c >= 0 {
+			rest, comment = rest[:c], rest[c:]
+		}
+		old := strings.TrimRight(rest, " \t")
+		if old == "" || strings.HasPrefix(old, "#") {
+			return "", fmt.Errorf("key %q is %w", strings.Join(keys, "."), errNotScalar)
+		}
+
+		quoted := value
+		if q := old[:1]; q == `"` || q == `'` {
+			quoted = q + value + q
+		}
+		lines[i] = line[:m[1]] + quoted + rest[len(old):] + comment
+		return strings.Join(lines, "\n"), nil
+	}
+
+	return "", fmt.Errorf("key %q is %w", strings.Join(keys, "."), errKeyNotFound)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVersionFile_Update(t *testing.T) {
+	type pattern struct {
+		exp     string
+		config  VersionFileConfig
+		content string
+	}
+	patterns := []pattern{
+		{"1.2.4\n", VersionFileConfig{}, "1.2.3\n"},
+		{"1.2.4", VersionFileConfig{}, "1.2.3"},
+		{"v1.2.4\n", VersionFileConfig{Format: "{tag}"}, "v1.2.3\n"},
+		{"package main\n\nconst Version = \"1.2.4\"\n", VersionFileConfig{Pattern: `Version = "(.*)"`}, "package main\n\nconst Version = \"1.2.3\"\n"},
+		{"{\n  \"name\": \"app\",\n  \"version\": \"1.2.4\",\n  \"private\": true\n}\n", VersionFileConfig{JSON: "version"}, "{\n  \"name\": \"app\",\n  \"version\": \"1.2.3\",\n  \"private\": true\n}\n"},
+		{`{"dependencies": {"version": "0.1.0"}, "version": "1.2.4"}`, VersionFileConfig{JSON: "version"}, `{"dependencies": {"version": "0.1.0"}, "version": "1.2.3"}`},
+		{`{"app": {"version": "1.2.4"}}`, VersionFileConfig{JSON: "app.version"}, `{"app": {"version": 1}}`},
+		{"apiVersion: v2\nversion: 0.1.0\nappVersion: \"1.2.4\" # app\n", VersionFileConfig{YAML: "appVersion"}, "apiVersion: v2\nversion: 0.1.0\nappVersion: \"1.2.3\" # app\n"},
+		{"image:\n  repository: app\n  tag: 1.2.4\ntag: latest\n", VersionFileConfig{YAML: "image.tag"}, "image:\n  repository: app\n  tag: 1.2.3\ntag: latest\n"},
+	}
+
+	for _, p := range patterns {
+		files, err := NewVersionFiles([]VersionFileConfig{withPath(p.config)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := files[0].Update(p.content, "v1.2.4", "1.2.4")
+		if err != nil {
+			t.Errorf("Error=%q, Config=%+v", err.Error(), p.config)
+			continue
+		}
+		if content != p.exp {
+			t.Errorf("Output=%q, Expected=%q", content, p.exp)
+		}
+	}
+}
+
+func TestVersionFile_UpdateError(t *testing.T) {
+	type pattern struct {
+		exp     string
+		config  VersionFileConfig
+		content string
+	}
+	patterns := []pattern{
+		{`VERSION: pattern "Version = \"(.*)\"" is not found`, VersionFileConfig{Pattern: `Version = "(.*)"`}, "package main\n"},
+		{`VERSION: key "version" is not found`, VersionFileConfig{JSON: "version"}, `{"name": "app"}`},
+		{`VERSION: key "app.version" is not a scalar`, VersionFileConfig{JSON: "app.version"}, `{"app": {"version": {}}}`},
+		{`VERSION: key "appVersion" is not found`, VersionFileConfig{YAML: "appVersion"}, "version: 0.1.0\n"},
+		{`VERSION: key "image" is not a scalar`, VersionFileConfig{YAML: "image"}, "image:\n  tag: 1.2.3\n"},
+	}
+
+	for _, p := range patterns {
+		files, err := NewVersionFiles([]VersionFileConfig{withPath(p.config)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = files[0].Update(p.content, "v1.2.4", "1.2.4")
+		if err == nil || err.Error() != p.exp {
+			t.Errorf("Output=%v, Expected=%q", err, p.exp)
+		}
+	}
+}
+
+func TestNewVersionFiles_Error(t *testing.T) {
+	type pattern struct {
+		exp    string
+		config VersionFileConfig
+	}
+	patterns := []pattern{
+		{"version file path is empty", VersionFileConfig{}},
+		{"version file VERSION must have only one of pattern, json and yaml", VersionFileConfig{Path: "VERSION", JSON: "version", YAML: "version"}},
+		{"pattern of version file VERSION has no group of the version", VersionFileConfig{Path: "VERSION", Pattern: `Version = ".*"`}},
+		{"invalid pattern of version file VERSION", VersionFileConfig{Path: "VERSION", Pattern: `(`}},
+	}
+
+	for _, p := range patterns {
+		_, err := NewVersionFiles([]VersionFileConfig{p.config})
+		if err == nil || !strings.HasPrefix(err.Error(), p.exp) {
+			t.Errorf("Output=%v, Expected=%q", err, p.exp)
+		}
+	}
+}
+
+func TestVersionFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"VERSION": "1.2.3\n", "CURRENT": "1.2.4\n"})
+
+	files, err := NewVersionFiles([]VersionFileConfig{{Path: filepath.Join(dir, "VERSION")}, {Path: filepath.Join(dir, "CURRENT")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := VersionFileChanges(files, "v1.2.4", "1.2.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// CURRENT already has the version.
+	info, err := os.Stat(filepath.Join(dir, "VERSION"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []FileChange{{Path: filepath.Join(dir, "VERSION"), Before: "1.2.3\n", After: "1.2.4\n", Exists: true, Mode: info.Mode().Perm()}}
+	if len(changes) != 1 || changes[0] != expected[0] {
+		t.Errorf("Output=%+v, Expected=%+v", changes, expected)
+	}
+
+	if err := WriteFileChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "VERSION"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "1.2.4\n" {
+		t.Errorf("Output=%q, Expected=%q", string(b), "1.2.4\n")
+	}
+}
+
+func TestRevertFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"version.sh": "echo 1.2.4\n", "CHANGELOG.md": "## v1.2.4\n", "HISTORY.md": "## v1.2.4\n"})
+	changes := []FileChange{
+		{Path: filepath.Join(dir, "version.sh"), Before: "echo 1.2.3\n", After: "echo 1.2.4\n", Exists: true, Mode: 0o755},
+		{Path: filepath.Join(dir, "CHANGELOG.md"), Before: "", After: "## v1.2.4\n"},
+		{Path: filepath.Join(dir, "HISTORY.md"), Before: "", After: "## v1.2.4\n", Exists: true, Mode: 0o644},
+		{Path: filepath.Join(dir, "NOT_WRITTEN"), Before: "", After: "1.2.4\n"},
+	}
+
+	if err := RevertFileChanges(changes); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "version.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "echo 1.2.3\n" {
+		t.Errorf("Output=%q, Expected=%q", string(b), "echo 1.2.3\n")
+	}
+	info, err := os.Stat(filepath.Join(dir, "version.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("Output=%v, Expected=%v", info.Mode().Perm(), os.FileMode(0o755))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "CHANGELOG.md")); !os.IsNotExist(err) {
+		t.Errorf("Created file is not removed: %v", err)
+	}
+	// the empty file existing before is kept.
+	if b, err := os.ReadFile(filepath.Join(dir, "HISTORY.md")); err != nil || string(b) != "" {
+		t.Errorf("Output=%q, Expected=%q, Error=%v", string(b), "", err)
+	}
+}
+
+func TestFileChange_Diff(t *testing.T) {
+	change := FileChange{
+		Path:   "package.json",
+		Before: "{\n  \"name\": \"app\",\n  \"version\": \"1.2.3\",\n  \"private\": true,\n  \"license\": \"MIT\",\n  \"main\": \"index.js\"\n}\n",
+		After:  "{\n  \"name\": \"app\",\n  \"version\": \"1.2.4\",\n  \"private\": true,\n  \"license\": \"MIT\",\n  \"main\": \"index.js\"\n}\n",
+	}
+
+	expected := "--- package.json\n"
+	expected = expected + "+++ package.json\n"
+	expected = expected + "    \"name\": \"app\",\n"
+	expected = expected + "-   \"version\": \"1.2.3\",\n"
+	expected = expected + "+   \"version\": \"1.2.4\",\n"
+	expected = expected + "    \"private\": true,"
+	if diff := change.Diff(); diff != expected {
+		t.Errorf("Output=%q, Expected=%q", diff, expected)
+	}
+}
+
+func TestVersionOf(t *testing.T) {
+	type pattern struct {
+		exp string
+		tag string
+	}
+	patterns := []pattern{
+		{"1.2.3", "v1.2.3"},
+		{"1.2.3-rc.1", "api/v1.2.3-rc.1"},
+		{"20180525.1", "release_20180525.1"},
+		{"1.2", "api/v1.2"},
+	}
+
+	for _, p := range patterns {
+		if version := VersionOf(autoScheme{}, p.tag); version != p.exp {
+			t.Errorf("Output=%q, Expected=%q", version, p.exp)
+		}
+	}
+}
+
+func withPath(config VersionFileConfig) VersionFileConfig {
+	config.Path = "VERSION"
+	return config
+}